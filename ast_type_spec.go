@@ -7,22 +7,93 @@ import (
 )
 
 // AstTypeSpec represents one ast.TypeSpec.
+//
+// It also represents anonymous struct and interface types of fields
+// (like "Opts struct{ A int }"), in this case TypeSpec.Name is nil and
+// ParentField is the field.
 type AstTypeSpec struct {
 	File     *File
 	TypeSpec *ast.TypeSpec
+
+	ParentField *Field
 }
 
 func (astTypeSpec AstTypeSpec) toType(expr ast.Expr) (types.TypeAndValue, error) {
 	return astTypeSpec.File.ToType(expr)
 }
 
-// Name returns the type name of the structure.
+// Object returns the type-checked object of the type definition (or nil if
+// there is no type information).
+func (astTypeSpec AstTypeSpec) Object() *types.TypeName {
+	pkg := astTypeSpec.File.Package
+	if pkg == nil || pkg.Info == nil {
+		return nil
+	}
+	typeName, _ := pkg.Info.Defs[astTypeSpec.TypeSpec.Name].(*types.TypeName)
+	return typeName
+}
+
+// Type returns the type-checked type of the type definition (or nil if
+// there is no type information).
+func (astTypeSpec AstTypeSpec) Type() types.Type {
+	if astTypeSpec.IsAnonymous() {
+		typ, err := astTypeSpec.toType(astTypeSpec.TypeSpec.Type)
+		if err != nil {
+			return nil
+		}
+		return typ.Type
+	}
+	obj := astTypeSpec.Object()
+	if obj == nil {
+		return nil
+	}
+	return obj.Type()
+}
+
+// Name returns the type name of the structure (or an empty string for
+// anonymous types).
 func (astTypeSpec AstTypeSpec) Name() string {
+	if astTypeSpec.IsAnonymous() {
+		return ""
+	}
 	return astTypeSpec.TypeSpec.Name.String()
 }
 
-// Methods returns all methods of the structure.
+// path returns the name of the type, or for an anonymous type the path
+// to it from the named type through the fields (like "Config.Server.TLS").
+func (astTypeSpec AstTypeSpec) path() string {
+	if !astTypeSpec.IsAnonymous() {
+		return astTypeSpec.Name()
+	}
+	return astTypeSpec.ParentField.Struct.path() + "." + astTypeSpec.ParentField.Name()
+}
+
+// IsAnonymous returns true if it is an anonymous type of a field (like
+// "Opts struct{ A int }").
+func (astTypeSpec AstTypeSpec) IsAnonymous() bool {
+	return astTypeSpec.TypeSpec.Name == nil
+}
+
+// TypeParams returns the type parameters of the type (or nil if the type
+// is not generic).
+func (astTypeSpec AstTypeSpec) TypeParams() TypeParams {
+	return newTypeParams(astTypeSpec.File, astTypeSpec.TypeSpec.TypeParams)
+}
+
+// IsGeneric returns true if the type has type parameters.
+func (astTypeSpec AstTypeSpec) IsGeneric() bool {
+	return astTypeSpec.TypeSpec.TypeParams != nil && len(astTypeSpec.TypeSpec.TypeParams.List) > 0
+}
+
+// Methods returns all methods declared for the type in the same package
+// (promoted methods are not included). It does not require type
+// information.
+//
+// See also MethodSet.
 func (astTypeSpec AstTypeSpec) Methods() Funcs {
+	if astTypeSpec.IsAnonymous() {
+		return nil
+	}
 	return astTypeSpec.File.Package.Funcs().FindMethodsOf(astTypeSpec.TypeSpec.Name.Name)
 }
 
@@ -40,5 +111,35 @@ func (astTypeSpec AstTypeSpec) MethodByName(methodName string) *Func {
 	}
 }
 
+// ToStruct returns the type definition as a Struct (or nil if it is not
+// a structure).
+func (astTypeSpec AstTypeSpec) ToStruct() *Struct {
+	structType, ok := astTypeSpec.TypeSpec.Type.(*ast.StructType)
+	if !ok || structType.Incomplete {
+		return nil
+	}
+	return &Struct{AstTypeSpec: astTypeSpec}
+}
+
+// ToInterface returns the type definition as an Interface (or nil if it is
+// not an interface).
+func (astTypeSpec AstTypeSpec) ToInterface() *Interface {
+	if _, ok := astTypeSpec.TypeSpec.Type.(*ast.InterfaceType); !ok {
+		return nil
+	}
+	return &Interface{AstTypeSpec: astTypeSpec}
+}
+
 // AstTypeSpecs represents multiple Type-s.
 type AstTypeSpecs []*AstTypeSpec
+
+// FindByName returns the type definition with the specified name (or nil
+// if there is no such type definition).
+func (astTypeSpecs AstTypeSpecs) FindByName(name string) *AstTypeSpec {
+	for _, astTypeSpec := range astTypeSpecs {
+		if astTypeSpec.Name() == name {
+			return astTypeSpec
+		}
+	}
+	return nil
+}
@@ -0,0 +1,501 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// CallKind defines how a callee is called.
+type CallKind uint
+
+const (
+	// CallKindUndefined is an undefined kind of a call.
+	CallKindUndefined = CallKind(iota)
+
+	// CallKindStatic is a direct call of a function or of a method
+	// of a concrete type.
+	CallKindStatic
+
+	// CallKindInterface is a call of a method through an interface: an edge
+	// is added for each candidate implementation found in the packages
+	// (or to the interface method itself if none found).
+	CallKindInterface
+
+	// CallKindFuncValue is a call of a variable of a function type, which
+	// value was determined by the assignments to the variable.
+	CallKindFuncValue
+)
+
+// String just implements fmt.Stringer
+func (kind CallKind) String() string {
+	switch kind {
+	case CallKindUndefined:
+		return "undefined"
+	case CallKindStatic:
+		return "static"
+	case CallKindInterface:
+		return "interface"
+	case CallKindFuncValue:
+		return "func_value"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// CallGraphNode is a function (or a method) in a CallGraph.
+type CallGraphNode struct {
+	// Object is the function.
+	Object *types.Func
+
+	// Func is the source code of the function (or nil if the function
+	// is declared outside of the packages of the call graph).
+	Func *Func
+
+	In  CallEdges
+	Out CallEdges
+
+	key string
+}
+
+// CallGraphNodes is a set of CallGraphNode-s.
+type CallGraphNodes []*CallGraphNode
+
+// Name returns the full name of the function, like "pkg/path.Func" or
+// "(*pkg/path.Type).Method".
+func (node CallGraphNode) Name() string {
+	return node.Object.FullName()
+}
+
+// String just implements fmt.Stringer
+func (node CallGraphNode) String() string {
+	return node.Name()
+}
+
+// CallEdge is a call from one function to another.
+type CallEdge struct {
+	Caller *CallGraphNode
+	Callee *CallGraphNode
+	Kind   CallKind
+
+	// CallExpr is the call in the source code of the caller.
+	CallExpr *ast.CallExpr
+	Position token.Position
+}
+
+// CallEdges is a set of CallEdge-s.
+type CallEdges []*CallEdge
+
+// String just implements fmt.Stringer
+func (edge CallEdge) String() string {
+	return fmt.Sprintf("%s -> %s (%s at %s)", edge.Caller, edge.Callee, edge.Kind, edge.Position)
+}
+
+// CallGraph is a static call graph of functions of packages.
+type CallGraph struct {
+	nodes map[string]*CallGraphNode
+	pkgs  Packages
+}
+
+// NewCallGraph builds the static call graph of all the functions declared
+// in the packages. Calls inside of function literals are attributed to
+// the enclosing function declaration.
+//
+// The packages should be opened without "onlyFiles".
+func NewCallGraph(pkgs Packages) (*CallGraph, error) {
+	graph := &CallGraph{
+		nodes: map[string]*CallGraphNode{},
+		pkgs:  pkgs,
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.FileSet == nil {
+			continue
+		}
+		if err := pkg.checkInfo(); err != nil {
+			return nil, err
+		}
+		for _, file := range pkg.Files {
+			if file.Package != pkg {
+				continue
+			}
+			for _, fn := range file.AllFuncs() {
+				if err := graph.addFunc(pkg, fn); err != nil {
+					return nil, fmt.Errorf("unable to process function '%s': %w", fn.FuncDecl.Name.Name, err)
+				}
+			}
+		}
+	}
+	return graph, nil
+}
+
+func (graph *CallGraph) node(pkg *Package, obj *types.Func) *CallGraphNode {
+	obj = obj.Origin()
+	key := pkg.objectKey(obj)
+	if node, ok := graph.nodes[key]; ok {
+		return node
+	}
+	node := &CallGraphNode{
+		Object: obj,
+		key:    key,
+	}
+	graph.nodes[key] = node
+	return node
+}
+
+func (graph *CallGraph) addEdge(pkg *Package, caller *CallGraphNode, callee *types.Func, kind CallKind, callExpr *ast.CallExpr) {
+	edge := &CallEdge{
+		Caller:   caller,
+		Callee:   graph.node(pkg, callee),
+		Kind:     kind,
+		CallExpr: callExpr,
+		Position: pkg.position(callExpr.Lparen),
+	}
+	caller.Out = append(caller.Out, edge)
+	edge.Callee.In = append(edge.Callee.In, edge)
+}
+
+func (graph *CallGraph) addFunc(pkg *Package, fn *Func) error {
+	obj, ok := pkg.Info.Defs[fn.FuncDecl.Name].(*types.Func)
+	if !ok {
+		return fmt.Errorf("no type information")
+	}
+	caller := graph.node(pkg, obj)
+	caller.Func = fn
+	if fn.FuncDecl.Body == nil {
+		return nil
+	}
+
+	funcValues := pkg.collectFuncValues(fn.FuncDecl.Body)
+	ast.Inspect(fn.FuncDecl.Body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ident := calleeIdent(callExpr.Fun)
+		if ident == nil {
+			return true
+		}
+		switch callee := pkg.Info.Uses[ident].(type) {
+		case *types.Func:
+			selectorExpr, _ := unparenExpr(callExpr.Fun).(*ast.SelectorExpr)
+			if selection := pkg.Info.Selections[selectorExpr]; selection != nil && types.IsInterface(selection.Recv()) {
+				graph.addInterfaceCall(pkg, caller, callee, callExpr)
+				return true
+			}
+			graph.addEdge(pkg, caller, callee, CallKindStatic, callExpr)
+		case *types.Var:
+			for _, value := range funcValues[callee] {
+				graph.addEdge(pkg, caller, value, CallKindFuncValue, callExpr)
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+func (graph *CallGraph) addInterfaceCall(pkg *Package, caller *CallGraphNode, method *types.Func, callExpr *ast.CallExpr) {
+	iface, ok := method.Type().(*types.Signature).Recv().Type().Underlying().(*types.Interface)
+	if !ok {
+		graph.addEdge(pkg, caller, method, CallKindInterface, callExpr)
+		return
+	}
+
+	found := false
+	for _, implPkg := range graph.pkgs {
+		for _, named := range (Packages{implPkg}).namedTypes() {
+			if types.IsInterface(named) {
+				continue
+			}
+			impl := lookupMethodByName(types.NewPointer(named), method.Name())
+			if impl == nil || !implementsByNames(types.NewPointer(named), iface) {
+				continue
+			}
+			graph.addEdge(implPkg, caller, impl, CallKindInterface, callExpr)
+			// the edge was created using positions of implPkg, but
+			// the call itself is in pkg
+			caller.Out[len(caller.Out)-1].Position = pkg.position(callExpr.Lparen)
+			found = true
+		}
+	}
+	if !found {
+		graph.addEdge(pkg, caller, method, CallKindInterface, callExpr)
+	}
+}
+
+// lookupMethodByName returns the method of the method set of the type
+// with the specified name.
+func lookupMethodByName(typ types.Type, name string) *types.Func {
+	methodSet := types.NewMethodSet(typ)
+	for idx := 0; idx < methodSet.Len(); idx++ {
+		if fn, ok := methodSet.At(idx).Obj().(*types.Func); ok && fn.Name() == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// implementsByNames is an analog of types.Implements, but it compares
+// signatures with identicalTypes, since the type and the interface could
+// be produced by independent type-checking passes (see also
+// CheckImplementation).
+func implementsByNames(typ types.Type, iface *types.Interface) bool {
+	for idx := 0; idx < iface.NumMethods(); idx++ {
+		want := iface.Method(idx)
+		have := lookupMethodByName(typ, want.Name())
+		if have == nil {
+			return false
+		}
+		if !identicalTypes(have.Type(), want.Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectFuncValues returns functions assigned to variables of function
+// types (only for variables which are assigned only with functions
+// and methods by their names, like "f := strings.TrimSpace").
+func (pkg *Package) collectFuncValues(body ast.Node) map[*types.Var][]*types.Func {
+	result := map[*types.Var][]*types.Func{}
+	unknown := map[*types.Var]struct{}{}
+	assign := func(lhs ast.Expr, rhs ast.Expr) {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			return
+		}
+		v, ok := pkg.Info.ObjectOf(ident).(*types.Var)
+		if !ok {
+			return
+		}
+		if _, ok := v.Type().Underlying().(*types.Signature); !ok {
+			return
+		}
+		var fn *types.Func
+		if rhsIdent := calleeIdent(rhs); rhsIdent != nil {
+			fn, _ = pkg.Info.Uses[rhsIdent].(*types.Func)
+		}
+		if fn == nil {
+			unknown[v] = struct{}{}
+			return
+		}
+		result[v] = append(result[v], fn)
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				for _, lhs := range node.Lhs {
+					assign(lhs, nil)
+				}
+				return true
+			}
+			for idx := range node.Lhs {
+				assign(node.Lhs[idx], node.Rhs[idx])
+			}
+		case *ast.ValueSpec:
+			for idx, name := range node.Names {
+				if idx < len(node.Values) && len(node.Names) == len(node.Values) {
+					assign(name, node.Values[idx])
+				}
+			}
+		case *ast.UnaryExpr:
+			// the variable could be modified through the pointer
+			if node.Op == token.AND {
+				assign(unparenExpr(node.X), nil)
+			}
+		}
+		return true
+	})
+	for v := range unknown {
+		delete(result, v)
+	}
+	return result
+}
+
+func unparenExpr(expr ast.Expr) ast.Expr {
+	for {
+		parenExpr, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = parenExpr.X
+	}
+}
+
+// calleeIdent returns the identifier of the function (or of the variable)
+// referenced by the expression, like "Println" for "fmt.Println" or
+// "Map" for "Map[int, string]".
+func calleeIdent(expr ast.Expr) *ast.Ident {
+	expr = unparenExpr(expr)
+	switch casted := expr.(type) {
+	case *ast.IndexExpr:
+		expr = casted.X
+	case *ast.IndexListExpr:
+		expr = casted.X
+	}
+	switch casted := unparenExpr(expr).(type) {
+	case *ast.Ident:
+		return casted
+	case *ast.SelectorExpr:
+		return casted.Sel
+	default:
+		return nil
+	}
+}
+
+// Nodes returns all the nodes of the call graph sorted by names.
+func (graph *CallGraph) Nodes() CallGraphNodes {
+	result := make(CallGraphNodes, 0, len(graph.nodes))
+	for _, node := range graph.nodes {
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name() != result[j].Name() {
+			return result[i].Name() < result[j].Name()
+		}
+		return result[i].key < result[j].key
+	})
+	return result
+}
+
+// Node returns the node of the function (or nil if there is no such
+// function in the call graph).
+func (graph *CallGraph) Node(fn *Func) *CallGraphNode {
+	pkg := fn.File.Package
+	if pkg == nil || pkg.Info == nil {
+		return nil
+	}
+	obj := pkg.Info.Defs[fn.FuncDecl.Name]
+	if obj == nil {
+		return nil
+	}
+	return graph.nodes[pkg.objectKey(obj)]
+}
+
+// Callers returns the calls of the function.
+func (graph *CallGraph) Callers(fn *Func) CallEdges {
+	node := graph.Node(fn)
+	if node == nil {
+		return nil
+	}
+	return node.In
+}
+
+// Callees returns the calls made by the function.
+func (graph *CallGraph) Callees(fn *Func) CallEdges {
+	node := graph.Node(fn)
+	if node == nil {
+		return nil
+	}
+	return node.Out
+}
+
+// Reaching returns all the nodes, which (directly or transitively) call
+// the function, sorted by names.
+func (graph *CallGraph) Reaching(fn *Func) CallGraphNodes {
+	target := graph.Node(fn)
+	if target == nil {
+		return nil
+	}
+	visited := map[*CallGraphNode]struct{}{target: {}}
+	queue := CallGraphNodes{target}
+	var result CallGraphNodes
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range node.In {
+			if _, ok := visited[edge.Caller]; ok {
+				continue
+			}
+			visited[edge.Caller] = struct{}{}
+			result = append(result, edge.Caller)
+			queue = append(queue, edge.Caller)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result
+}
+
+// Path returns the shortest chain of calls from one function to another
+// (or nil if the latter is not reachable from the former).
+func (graph *CallGraph) Path(from, to *Func) CallEdges {
+	fromNode, toNode := graph.Node(from), graph.Node(to)
+	if fromNode == nil || toNode == nil {
+		return nil
+	}
+	via := map[*CallGraphNode]*CallEdge{}
+	visited := map[*CallGraphNode]struct{}{fromNode: {}}
+	queue := CallGraphNodes{fromNode}
+	for len(queue) > 0 && via[toNode] == nil {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range node.Out {
+			if _, ok := visited[edge.Callee]; ok {
+				continue
+			}
+			visited[edge.Callee] = struct{}{}
+			via[edge.Callee] = edge
+			queue = append(queue, edge.Callee)
+		}
+	}
+	if via[toNode] == nil {
+		return nil
+	}
+	var result CallEdges
+	for node := toNode; node != fromNode; node = via[node].Caller {
+		result = append(CallEdges{via[node]}, result...)
+	}
+	return result
+}
+
+// WriteDOT writes the call graph in the Graphviz DOT format. Interface
+// calls are drawn dashed and calls of function values are drawn dotted.
+func (graph *CallGraph) WriteDOT(w io.Writer) error {
+	nodes := graph.Nodes()
+	ids := map[*CallGraphNode]int{}
+	if _, err := fmt.Fprintln(w, "digraph callgraph {"); err != nil {
+		return err
+	}
+	for idx, node := range nodes {
+		ids[node] = idx
+		style := ""
+		if node.Func == nil {
+			style = ", style=dashed"
+		}
+		if _, err := fmt.Fprintf(w, "\tn%d [label=%s%s];\n", idx, strconv.Quote(node.Name()), style); err != nil {
+			return err
+		}
+	}
+	for _, node := range nodes {
+		type edgeKey struct {
+			callee *CallGraphNode
+			kind   CallKind
+		}
+		written := map[edgeKey]struct{}{}
+		for _, edge := range node.Out {
+			key := edgeKey{callee: edge.Callee, kind: edge.Kind}
+			if _, ok := written[key]; ok {
+				continue
+			}
+			written[key] = struct{}{}
+			style := ""
+			switch edge.Kind {
+			case CallKindInterface:
+				style = " [style=dashed]"
+			case CallKindFuncValue:
+				style = " [style=dotted]"
+			}
+			if _, err := fmt.Fprintf(w, "\tn%d -> n%d%s;\n", ids[node], ids[edge.Callee], style); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
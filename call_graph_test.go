@@ -0,0 +1,55 @@
+package gosrc_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestCallGraph(t *testing.T) {
+	pkg := openTestPackage(t, "callgraph")
+	graph, err := gosrc.NewCallGraph(gosrc.Packages{pkg})
+	require.NoError(t, err)
+
+	funcs := pkg.AllFuncs()
+	serve := funcs.FindByName("Serve")[0]
+	callees := graph.Callees(serve)
+	require.Len(t, callees, 2)
+	for _, edge := range callees {
+		require.Equal(t, gosrc.CallKindInterface, edge.Kind)
+		require.Equal(t, "Handle", edge.Callee.Object.Name())
+		require.NotNil(t, edge.Callee.Func)
+	}
+
+	main := funcs.FindByName("Main")[0]
+	var kinds []gosrc.CallKind
+	var names []string
+	for _, edge := range graph.Callees(main) {
+		kinds = append(kinds, edge.Kind)
+		names = append(names, edge.Callee.Object.Name())
+	}
+	require.Equal(t, []gosrc.CallKind{gosrc.CallKindFuncValue, gosrc.CallKindStatic}, kinds)
+	require.Equal(t, []string{"normalize", "Serve"}, names)
+
+	deprecated := funcs.FindByName("deprecated")[0]
+	var reaching []string
+	for _, node := range graph.Reaching(deprecated) {
+		reaching = append(reaching, node.Object.Name())
+	}
+	require.Equal(t, []string{"Handle", "Main", "Serve"}, reaching)
+
+	path := graph.Path(main, deprecated)
+	require.Len(t, path, 3)
+	require.Nil(t, graph.Path(deprecated, main))
+
+	normalize := funcs.FindByName("normalize")[0]
+	require.Len(t, graph.Callers(normalize), 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteDOT(&buf))
+	require.Contains(t, buf.String(), "digraph callgraph {")
+	require.Contains(t, buf.String(), `"strings.TrimSpace"`)
+	require.Contains(t, buf.String(), "[style=dashed];")
+}
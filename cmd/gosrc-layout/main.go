@@ -0,0 +1,104 @@
+// Command gosrc-layout reports the differences of memory layouts
+// of structures between GOARCHes and verifies the layouts declared by
+// "gosrc:layout" directives.
+//
+// Usage:
+//
+//	gosrc-layout [flags] <package path> [struct name...]
+//
+// It exits with code 1 if a declared layout does not match the actual one
+// (or if a directive is malformed).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xaionaro-go/gosrc"
+)
+
+func main() {
+	os.Exit(run(os.Args[0], os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command and returns the exit code.
+func run(name string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	goarchesFlag := flags.String("goarch", strings.Join(gosrc.DefaultLayoutGOARCHes, ","), "comma-separated list of GOARCHes to compare")
+	allFlag := flags.Bool("all", false, "report also structures with the same layout for all the GOARCHes")
+	verifyOnlyFlag := flags.Bool("verify", false, "only verify the layouts declared by directives, do not report differences")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s [flags] <package path> [struct name...]\n", name)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	dir, err := gosrc.OpenDirectoryByPkgPath(&build.Default, flags.Arg(0), false, false, false, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "unable to open package '%s': %v\n", flags.Arg(0), err)
+		return 2
+	}
+	structNames := map[string]bool{}
+	for _, name := range flags.Args()[1:] {
+		structNames[name] = true
+	}
+	goarches := strings.Split(*goarchesFlag, ",")
+
+	hasMismatches := false
+	for _, pkg := range dir.Packages {
+		for _, file := range pkg.Files {
+			if file.Package != pkg {
+				// test files and files excluded by build tags have
+				// no type information
+				continue
+			}
+			for _, _struct := range file.Structs() {
+				if len(structNames) > 0 && !structNames[_struct.Name()] {
+					continue
+				}
+				if _struct.IsGeneric() {
+					continue
+				}
+
+				mismatches, err := _struct.VerifyLayout()
+				if err != nil {
+					fmt.Fprintf(stderr, "unable to verify the layout of %s: %v\n", _struct, err)
+					hasMismatches = true
+					continue
+				}
+				for _, mismatch := range mismatches {
+					fmt.Fprintln(stdout, mismatch)
+					hasMismatches = true
+				}
+				if *verifyOnlyFlag {
+					continue
+				}
+
+				comparison, err := _struct.CompareLayouts(goarches...)
+				if err != nil {
+					fmt.Fprintf(stderr, "unable to compare layouts of %s: %v\n", _struct, err)
+					return 2
+				}
+				if comparison.IsConsistent() && !*allFlag {
+					continue
+				}
+				fmt.Fprintf(stdout, "%s: %s\n", _struct.Position(), comparison)
+			}
+		}
+	}
+	if hasMismatches {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run("gosrc-layout", []string{"./testdata/testonly"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Empty(t, stdout.String())
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run("gosrc-layout", []string{"-verify", "./testdata/mismatch"}, &stdout, &stderr)
+	require.Equal(t, 1, code, stderr.String())
+	require.Contains(t, stdout.String(), "Header")
+
+	code = run("gosrc-layout", nil, &stdout, &stderr)
+	require.Equal(t, 2, code)
+}
@@ -0,0 +1,8 @@
+package mismatch
+
+// Header declares a wrong size.
+//
+//gosrc:layout size=4
+type Header struct {
+	Length uint64
+}
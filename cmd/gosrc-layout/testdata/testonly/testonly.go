@@ -0,0 +1,2 @@
+// Package testonly has a structure only in a test file.
+package testonly
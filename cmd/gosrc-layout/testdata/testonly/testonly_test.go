@@ -0,0 +1,6 @@
+package testonly
+
+type fixture struct {
+	A int
+	B bool
+}
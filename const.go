@@ -0,0 +1,71 @@
+package gosrc
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Const represents one specification of constants of a source code file
+// (like "A, B = 1, 2"), which could be a part of a grouped declaration.
+type Const struct {
+	*ast.ValueSpec
+	GenDecl *ast.GenDecl
+	File    *File
+}
+
+// Consts is a set of Const-s.
+type Consts []*Const
+
+// Names returns the names of the constants.
+func (_const Const) Names() []string {
+	var result []string
+	for _, ident := range _const.ValueSpec.Names {
+		result = append(result, ident.Name)
+	}
+	return result
+}
+
+// Consts returns all package-level constants defined in the file.
+func (file *File) Consts() Consts {
+	var result Consts
+	for _, decl := range file.Ast.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			result = append(result, &Const{
+				ValueSpec: valueSpec,
+				GenDecl:   genDecl,
+				File:      file,
+			})
+		}
+	}
+	return result
+}
+
+// Consts returns all package-level constants of the package.
+func (pkg Package) Consts() Consts {
+	var result Consts
+	for _, file := range pkg.Files {
+		result = append(result, file.Consts()...)
+	}
+	return result
+}
+
+// FindByName returns the specification which declares the constant
+// with the specified name (or nil if there is no such constant).
+func (consts Consts) FindByName(name string) *Const {
+	for _, _const := range consts {
+		for _, ident := range _const.ValueSpec.Names {
+			if ident.Name == name {
+				return _const
+			}
+		}
+	}
+	return nil
+}
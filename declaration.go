@@ -0,0 +1,111 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// Declaration describes where an object is declared.
+type Declaration struct {
+	// Object is the declared object.
+	Object types.Object
+
+	// Position is the position of the declaring identifier (it is
+	// invalid for predeclared objects).
+	Position token.Position
+
+	// Package is the package where the object is declared (or nil if
+	// the package is not among the loaded ones).
+	Package *Package
+
+	// File is the file where the object is declared (or nil, see Package).
+	File *File
+
+	// Ident is the declaring identifier (or nil, see Package).
+	Ident *ast.Ident
+}
+
+// String just implements fmt.Stringer
+func (decl Declaration) String() string {
+	if !decl.Position.IsValid() {
+		return fmt.Sprintf("%s (predeclared)", decl.Object)
+	}
+	return fmt.Sprintf("%s at %s", decl.Object, decl.Position)
+}
+
+// DeclarationOf returns the declaration of the object used (or defined)
+// by the identifier of this package. The declaration is looked up in
+// this package and in the specified packages (for example,
+// Loader.Packages()); if it is not found there, then only Object and
+// Position are set.
+//
+// If the identifier both uses and defines objects (like "Meta" in an
+// embedded field "*other.Meta"), then the used object is preferred.
+func (pkg *Package) DeclarationOf(ident *ast.Ident, lookupPkgs ...*Package) (*Declaration, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	obj := pkg.Info.Uses[ident]
+	if obj == nil {
+		var err error
+		obj, err = pkg.ObjectOf(ident)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decl := &Declaration{
+		Object:   obj,
+		Position: pkg.position(obj.Pos()),
+	}
+	if !decl.Position.IsValid() {
+		return decl, nil
+	}
+
+	// Objects are compared by positions, since the packages could be
+	// type-checked independently (thus the same declaration could be
+	// represented by different objects).
+	for _, lookupPkg := range append(Packages{pkg}, lookupPkgs...) {
+		if lookupPkg == nil || lookupPkg.FileSet == nil {
+			continue
+		}
+		for _, file := range lookupPkg.Files {
+			if file.Path != decl.Position.Filename {
+				continue
+			}
+			tokenFile := lookupPkg.FileSet.File(file.Ast.Pos())
+			if tokenFile == nil || decl.Position.Offset > tokenFile.Size() {
+				continue
+			}
+			declIdent := findIdentAt(file.Ast, tokenFile.Pos(decl.Position.Offset))
+			if declIdent == nil {
+				continue
+			}
+			decl.Package = lookupPkg
+			decl.File = file
+			decl.Ident = declIdent
+			return decl, nil
+		}
+	}
+	return decl, nil
+}
+
+func findIdentAt(node ast.Node, pos token.Pos) *ast.Ident {
+	var result *ast.Ident
+	ast.Inspect(node, func(node ast.Node) bool {
+		if result != nil || node == nil {
+			return false
+		}
+		if pos < node.Pos() || pos >= node.End() {
+			return false
+		}
+		if ident, ok := node.(*ast.Ident); ok && ident.Pos() == pos {
+			result = ident
+			return false
+		}
+		return true
+	})
+	return result
+}
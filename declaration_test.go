@@ -0,0 +1,54 @@
+package gosrc_test
+
+import (
+	"go/ast"
+	"go/build"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestPackageDeclarationOf(t *testing.T) {
+	pkg := openTestPackage(t, "embedding")
+	otherPkg := openTestPackage(t, "embedding/other")
+
+	fields, err := findStruct(t, pkg, "Base").Fields()
+	require.NoError(t, err)
+	metaIdent := fields[0].Type.(*ast.StarExpr).X.(*ast.SelectorExpr).Sel
+
+	obj, err := pkg.ObjectOf(metaIdent)
+	require.NoError(t, err)
+	require.Equal(t, "Meta", obj.Name())
+	require.True(t, obj.(*types.Var).Embedded())
+
+	decl, err := pkg.DeclarationOf(metaIdent)
+	require.NoError(t, err)
+	require.True(t, decl.Position.IsValid())
+	require.Nil(t, decl.Package)
+
+	decl, err = pkg.DeclarationOf(metaIdent, otherPkg)
+	require.NoError(t, err)
+	require.Equal(t, otherPkg, decl.Package)
+	require.Equal(t, "Meta", decl.Ident.Name)
+	require.Equal(t, "Meta", findStruct(t, otherPkg, "Meta").TypeSpec.Name.Name)
+	require.Equal(t, findStruct(t, otherPkg, "Meta").TypeSpec.Name, decl.Ident)
+
+	_, err = pkg.ToType(&ast.Ident{Name: "unknown"})
+	require.Error(t, err)
+}
+
+func TestLoaderDeclarationOf(t *testing.T) {
+	pkg := openTestPackage(t, "embedding")
+	fields, err := findStruct(t, pkg, "Base").Fields()
+	require.NoError(t, err)
+	metaIdent := fields[0].Type.(*ast.StarExpr).X.(*ast.SelectorExpr).Sel
+
+	loader := gosrc.NewLoader(&build.Default, nil)
+	decl, err := loader.DeclarationOf(pkg, metaIdent)
+	require.NoError(t, err)
+	require.NotNil(t, decl.Package)
+	require.Equal(t, "other", decl.Package.Name)
+	require.Equal(t, "Meta", decl.Ident.Name)
+}
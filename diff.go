@@ -0,0 +1,167 @@
+package gosrc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the amount of unchanged lines around changes
+// in a unified diff.
+const diffContextLines = 3
+
+// maxDiffMatrixSize limits the memory used to compare lines, if
+// the changed part is bigger, then it is considered replaced entirely.
+const maxDiffMatrixSize = 16 << 20
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+
+	// aIdx and bIdx are the indexes of the lines before the operation.
+	aIdx int
+	bIdx int
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the operations to transform a to b.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for idx := 0; idx < prefix; idx++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[idx], aIdx: idx, bIdx: idx})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	aIdx, bIdx := prefix, prefix
+	if len(midA)*len(midB) > maxDiffMatrixSize {
+		for _, line := range midA {
+			ops = append(ops, diffOp{kind: '-', line: line, aIdx: aIdx, bIdx: bIdx})
+			aIdx++
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{kind: '+', line: line, aIdx: aIdx, bIdx: bIdx})
+			bIdx++
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence
+		// of midA[i:] and midB[j:]
+		lcs := make([][]int32, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				switch {
+				case midA[i] == midB[j]:
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				case lcs[i+1][j] >= lcs[i][j+1]:
+					lcs[i][j] = lcs[i+1][j]
+				default:
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) || j < len(midB) {
+			switch {
+			case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+				ops = append(ops, diffOp{kind: ' ', line: midA[i], aIdx: aIdx, bIdx: bIdx})
+				i, j, aIdx, bIdx = i+1, j+1, aIdx+1, bIdx+1
+			case j >= len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, diffOp{kind: '-', line: midA[i], aIdx: aIdx, bIdx: bIdx})
+				i, aIdx = i+1, aIdx+1
+			default:
+				ops = append(ops, diffOp{kind: '+', line: midB[j], aIdx: aIdx, bIdx: bIdx})
+				j, bIdx = j+1, bIdx+1
+			}
+		}
+	}
+
+	for idx := len(a) - suffix; idx < len(a); idx++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[idx], aIdx: aIdx, bIdx: bIdx})
+		aIdx, bIdx = aIdx+1, bIdx+1
+	}
+	return ops
+}
+
+// unifiedDiff returns the difference between a and b in the unified
+// diff format (or an empty string if they are equal).
+func unifiedDiff(aName, bName string, a, b []byte) string {
+	ops := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	var result strings.Builder
+	for idx := 0; idx < len(ops); {
+		if ops[idx].kind == ' ' {
+			idx++
+			continue
+		}
+
+		start := idx - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		lastChange := idx
+		end := idx
+		for ; end < len(ops); end++ {
+			if ops[end].kind != ' ' {
+				lastChange = end
+				continue
+			}
+			if end-lastChange > 2*diffContextLines {
+				break
+			}
+		}
+		end = lastChange + diffContextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		if result.Len() == 0 {
+			fmt.Fprintf(&result, "--- %s\n+++ %s\n", aName, bName)
+		}
+		var aLen, bLen int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		aStart, bStart := ops[start].aIdx, ops[start].bIdx
+		if aLen > 0 {
+			aStart++
+		}
+		if bLen > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&result, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, op := range ops[start:end] {
+			result.WriteByte(op.kind)
+			result.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		idx = end
+	}
+	return result.String()
+}
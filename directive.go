@@ -0,0 +1,437 @@
+package gosrc
+
+import (
+	"encoding"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var directiveHeadRegexp = regexp.MustCompile(`^//([A-Za-z_][A-Za-z0-9_.\-]*):(\S+)`)
+var directiveKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// Directive is a machine-readable comment like:
+//
+//	//gen:builder skip name=Builder prefix="With "
+//
+// where "gen" is the namespace, "builder" is the key, "skip" is
+// a positional argument and "name" and "prefix" are options. Values could
+// be quoted (Go syntax) to contain spaces.
+//
+// Only "//"-comments without a space after "//" are directives.
+type Directive struct {
+	Comment  *ast.Comment
+	Position token.Position
+
+	Namespace string
+	Key       string
+	Args      []string
+	Options   map[string]string
+}
+
+// Directives is a set of Directive-s.
+type Directives []*Directive
+
+// Name returns the name of the directive, like "gen:builder".
+func (directive Directive) Name() string {
+	return directive.Namespace + ":" + directive.Key
+}
+
+// String just implements fmt.Stringer
+func (directive Directive) String() string {
+	return directive.Comment.Text
+}
+
+// HasArg returns true if there is the positional argument (for example
+// a flag like "skip" in "//gen:builder skip").
+func (directive Directive) HasArg(arg string) bool {
+	for _, candidate := range directive.Args {
+		if candidate == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// Option returns the value of the option and true if the option is set.
+func (directive Directive) Option(name string) (string, bool) {
+	value, ok := directive.Options[name]
+	return value, ok
+}
+
+func (directive Directive) errorf(format string, args ...interface{}) ErrMalformedDirective {
+	return ErrMalformedDirective{
+		Position: directive.Position,
+		Text:     directive.Comment.Text,
+		Err:      fmt.Errorf(format, args...),
+	}
+}
+
+// FindByName returns the first directive with the specified name (like
+// "gen:builder"), or nil if there is no such directive.
+func (directives Directives) FindByName(name string) *Directive {
+	for _, directive := range directives {
+		if directive.Name() == name {
+			return directive
+		}
+	}
+	return nil
+}
+
+// FilterByName returns only the directives with the specified name.
+func (directives Directives) FilterByName(name string) Directives {
+	var result Directives
+	for _, directive := range directives {
+		if directive.Name() == name {
+			result = append(result, directive)
+		}
+	}
+	return result
+}
+
+// FilterByNamespace returns only the directives of the specified
+// namespace (like "gen").
+func (directives Directives) FilterByNamespace(namespace string) Directives {
+	var result Directives
+	for _, directive := range directives {
+		if directive.Namespace == namespace {
+			result = append(result, directive)
+		}
+	}
+	return result
+}
+
+// parseDirectives returns directives of the comment groups (nil groups
+// are skipped, and the same group is never parsed twice). Malformed
+// directives are returned as ErrMalformedDirectives, but the valid ones
+// are returned anyway.
+func parseDirectives(pkg *Package, commentGroups ...*ast.CommentGroup) (Directives, error) {
+	var (
+		result Directives
+		errs   ErrMalformedDirectives
+	)
+	seen := map[*ast.CommentGroup]struct{}{}
+	for _, commentGroup := range commentGroups {
+		if commentGroup == nil {
+			continue
+		}
+		if _, ok := seen[commentGroup]; ok {
+			continue
+		}
+		seen[commentGroup] = struct{}{}
+		for _, comment := range commentGroup.List {
+			directive, err := parseDirective(comment, pkg.position(comment.Slash))
+			if err != nil {
+				errs = append(errs, *err)
+				continue
+			}
+			if directive != nil {
+				result = append(result, directive)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+// parseDirective returns nil (and no error) if the comment is not
+// a directive.
+func parseDirective(comment *ast.Comment, position token.Position) (*Directive, *ErrMalformedDirective) {
+	match := directiveHeadRegexp.FindStringSubmatch(comment.Text)
+	if match == nil || strings.HasPrefix(match[2], "/") {
+		// not a directive (or a URL like "//http://example.com")
+		return nil, nil
+	}
+
+	directive := &Directive{
+		Comment:   comment,
+		Position:  position,
+		Namespace: match[1],
+		Key:       match[2],
+		Options:   map[string]string{},
+	}
+	if !directiveKeyRegexp.MatchString(directive.Key) {
+		err := directive.errorf("invalid key <%s>", directive.Key)
+		return nil, &err
+	}
+
+	tokens, err := splitDirectiveArgs(comment.Text[len(match[0]):])
+	if err != nil {
+		err := directive.errorf("%w", err)
+		return nil, &err
+	}
+	for _, arg := range tokens {
+		eqIdx := strings.IndexByte(arg, '=')
+		if eqIdx < 0 || strings.ContainsAny(arg[:eqIdx], "\"`") {
+			value, err := unquoteDirectiveValue(arg)
+			if err != nil {
+				err := directive.errorf("%w", err)
+				return nil, &err
+			}
+			directive.Args = append(directive.Args, value)
+			continue
+		}
+		name := arg[:eqIdx]
+		if name == "" {
+			err := directive.errorf("option with an empty name: <%s>", arg)
+			return nil, &err
+		}
+		if _, ok := directive.Options[name]; ok {
+			err := directive.errorf("option '%s' is set multiple times", name)
+			return nil, &err
+		}
+		value, err := unquoteDirectiveValue(arg[eqIdx+1:])
+		if err != nil {
+			err := directive.errorf("invalid value of option '%s': %w", name, err)
+			return nil, &err
+		}
+		directive.Options[name] = value
+	}
+	return directive, nil
+}
+
+// splitDirectiveArgs splits the arguments by spaces, taking into account
+// quoted strings.
+func splitDirectiveArgs(s string) ([]string, error) {
+	var (
+		result  []string
+		current strings.Builder
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case quote == 0 && unicode.IsSpace(r):
+			if current.Len() > 0 {
+				result = append(result, current.String())
+				current.Reset()
+			}
+			continue
+		case quote == 0 && (r == '"' || r == '`'):
+			quote = r
+		case quote == '"' && r == '\\' && !escaped:
+			escaped = true
+			current.WriteRune(r)
+			continue
+		case quote != 0 && r == quote && !escaped:
+			quote = 0
+		}
+		escaped = false
+		current.WriteRune(r)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quoted string <%s>", current.String())
+	}
+	if current.Len() > 0 {
+		result = append(result, current.String())
+	}
+	return result, nil
+}
+
+func unquoteDirectiveValue(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) && !strings.HasPrefix(s, "`") {
+		return s, nil
+	}
+	return strconv.Unquote(s)
+}
+
+// Unmarshal stores the arguments and options of the directive into
+// the structure pointed by dst.
+//
+// Options are mapped to the exported fields by the "directive" tag (like
+// `directive:"name"`), or by the lower-cased field name if there is
+// no tag. Fields with tag `directive:"-"` are skipped. A positional argument
+// equal to the name of a bool field sets it to true (like "skip" in
+// "//gen:builder skip"). The other positional arguments are stored into
+// a []string field with tag `directive:",args"`.
+//
+// Supported types of fields are: string, bool, integers, floats,
+// time.Duration, []string (a comma-separated value) and types
+// implementing encoding.TextUnmarshaler.
+//
+// Unknown options and unexpected positional arguments are reported as
+// ErrMalformedDirective.
+func (directive Directive) Unmarshal(dst interface{}) error {
+	ptr := reflect.ValueOf(dst)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a non-nil pointer to a struct, got %T", dst)
+	}
+	v := ptr.Elem()
+	t := v.Type()
+
+	usedOptions := map[string]struct{}{}
+	usedArgs := map[int]struct{}{}
+	argsFieldIdx := -1
+	for fieldIdx := 0; fieldIdx < t.NumField(); fieldIdx++ {
+		structField := t.Field(fieldIdx)
+		if structField.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := strings.ToLower(structField.Name)
+		if tag, ok := structField.Tag.Lookup("directive"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag == ",args" {
+				argsFieldIdx = fieldIdx
+				continue
+			}
+			name = tag
+		}
+
+		fieldValue := v.Field(fieldIdx)
+		if value, ok := directive.Options[name]; ok {
+			usedOptions[name] = struct{}{}
+			if err := setDirectiveValue(fieldValue, value); err != nil {
+				return directive.errorf("invalid value of option '%s': %w", name, err)
+			}
+			continue
+		}
+		if fieldValue.Kind() != reflect.Bool {
+			continue
+		}
+		for argIdx, arg := range directive.Args {
+			if arg == name {
+				usedArgs[argIdx] = struct{}{}
+				fieldValue.SetBool(true)
+			}
+		}
+	}
+
+	for name := range directive.Options {
+		if _, ok := usedOptions[name]; !ok {
+			return directive.errorf("unknown option '%s'", name)
+		}
+	}
+	var args []string
+	for argIdx, arg := range directive.Args {
+		if _, ok := usedArgs[argIdx]; ok {
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if argsFieldIdx < 0 {
+		return directive.errorf("unexpected argument '%s'", args[0])
+	}
+	argsField := v.Field(argsFieldIdx)
+	if argsField.Type() != reflect.TypeOf([]string(nil)) {
+		return fmt.Errorf("field '%s' with tag `directive:\",args\"` should be of type []string", t.Field(argsFieldIdx).Name)
+	}
+	argsField.Set(reflect.ValueOf(args))
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func setDirectiveValue(v reflect.Value, value string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if v.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for idx, item := range items {
+			slice.Index(idx).SetString(item)
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Directives returns the directives of the type definition: of its doc
+// comment (including the doc comment of the grouped declaration
+// "type ( ... )") and of its line comment.
+//
+// If some directives are malformed, then ErrMalformedDirectives is
+// returned together with the valid directives.
+func (astTypeSpec AstTypeSpec) Directives() (Directives, error) {
+	var genDeclDoc *ast.CommentGroup
+	if genDecl := astTypeSpec.File.genDeclOf(astTypeSpec.TypeSpec); genDecl != nil {
+		genDeclDoc = genDecl.Doc
+	}
+	return parseDirectives(astTypeSpec.File.Package, genDeclDoc, astTypeSpec.TypeSpec.Doc, astTypeSpec.TypeSpec.Comment)
+}
+
+// Directives returns the directives of the doc comment and of the line
+// comment of the field (see AstTypeSpec.Directives).
+func (field Field) Directives() (Directives, error) {
+	return parseDirectives(field.Struct.File.Package, field.Field.Doc, field.Field.Comment)
+}
+
+// Directives returns the directives of the doc comment of the function
+// (see AstTypeSpec.Directives).
+func (fn Func) Directives() (Directives, error) {
+	return parseDirectives(fn.File.Package, fn.FuncDecl.Doc)
+}
+
+// Directives returns the directives of the doc comment and of the line
+// comment of the method (see AstTypeSpec.Directives).
+func (method InterfaceMethod) Directives() (Directives, error) {
+	return parseDirectives(method.Interface.File.Package, method.Field.Doc, method.Field.Comment)
+}
+
+// Directives returns the directives of the doc comment (including the doc
+// comment of the grouped declaration "const ( ... )") and of the line
+// comment of the constants (see AstTypeSpec.Directives).
+func (_const Const) Directives() (Directives, error) {
+	return parseDirectives(_const.File.Package, _const.GenDecl.Doc, _const.ValueSpec.Doc, _const.ValueSpec.Comment)
+}
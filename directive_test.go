@@ -0,0 +1,103 @@
+package gosrc_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestDirectives(t *testing.T) {
+	pkg := openTestPackage(t, "directives")
+	file := pkg.Files[0]
+
+	structs := file.StructsWithMagicComment("generate")
+	require.Len(t, structs, 1)
+	user := structs[0]
+	require.Equal(t, "User", user.Name())
+
+	directives, err := user.Directives()
+	require.NoError(t, err)
+	require.Len(t, directives, 2)
+	builder := directives.FindByName("gen:builder")
+	require.NotNil(t, builder)
+	require.Equal(t, []string{"skip", "extra"}, builder.Args)
+	require.Equal(t, 21, builder.Position.Line)
+
+	var opts struct {
+		Skip    bool
+		Name    string
+		Timeout time.Duration
+		Tags    []string
+		Rest    []string `directive:",args"`
+	}
+	require.NoError(t, builder.Unmarshal(&opts))
+	require.True(t, opts.Skip)
+	require.Equal(t, "UserBuilder", opts.Name)
+	require.Equal(t, 5*time.Second, opts.Timeout)
+	require.Equal(t, []string{"a", "b"}, opts.Tags)
+	require.Equal(t, []string{"extra"}, opts.Rest)
+
+	var strict struct {
+		Name string
+	}
+	err = builder.Unmarshal(&strict)
+	var errDirective gosrc.ErrMalformedDirective
+	require.True(t, errors.As(err, &errDirective))
+	require.Equal(t, 21, errDirective.Position.Line)
+
+	fields, err := user.Fields()
+	require.NoError(t, err)
+	directives, err = fields[0].Directives()
+	require.NoError(t, err)
+	var column struct {
+		Primary bool
+		Name    string
+	}
+	require.NoError(t, directives.FindByName("db:column").Unmarshal(&column))
+	require.Equal(t, "id", column.Name)
+	require.True(t, column.Primary)
+
+	directives, err = fields[1].Directives()
+	require.NoError(t, err)
+	value, _ := directives[0].Option("name")
+	require.Equal(t, "user name", value)
+
+	_, err = fields[2].Directives()
+	var errs gosrc.ErrMalformedDirectives
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	require.Equal(t, 31, errs[0].Position.Line)
+
+	directives, err = fields[3].Directives()
+	require.NoError(t, err)
+	require.Empty(t, directives)
+
+	kind := pkg.AstTypeSpecs().FindByName("Kind")
+	directives, err = kind.Directives()
+	require.NoError(t, err)
+	require.Equal(t, "Kind", directives.FindByName("gen:enum").Options["prefix"])
+
+	consts := pkg.Consts()
+	require.Len(t, consts, 2)
+	directives, err = consts.FindByName("KindA").Directives()
+	require.NoError(t, err)
+	require.Len(t, directives, 2)
+	require.Len(t, directives.FilterByNamespace("gen"), 2)
+	require.Equal(t, "A kind", directives.FilterByName("gen:value")[0].Options["name"])
+	directives, err = consts.FindByName("KindB").Directives()
+	require.NoError(t, err)
+	require.Equal(t, "B", directives.FindByName("gen:value").Options["name"])
+
+	handle := pkg.AllFuncs().FindByName("Handle")[0]
+	directives, err = handle.Directives()
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET", "/users"}, directives.FindByName("http:route").Args)
+
+	store := file.Interfaces()[0]
+	directives, err = store.ExplicitMethods()[0].Directives()
+	require.NoError(t, err)
+	require.Equal(t, "1s", directives.FindByName("rpc:method").Options["timeout"])
+}
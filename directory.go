@@ -18,6 +18,8 @@ import (
 type Directory struct {
 	FileSet  *token.FileSet
 	Packages Packages
+
+	symbolIndex *SymbolIndex
 }
 
 func normalizePkgPath(
@@ -146,7 +148,9 @@ func OpenDirectoryByPkgPath(
 	var conf types.Config
 	var pkgRaw *types.Package
 	if !onlyFiles {
-		conf = types.Config{Importer: importer.ForCompiler(token.NewFileSet(), "source", nil)}
+		// The importer shares the FileSet, so positions of imported objects
+		// could be resolved as well.
+		conf = types.Config{Importer: importer.ForCompiler(directory.FileSet, "source", nil)}
 		// Unfortunately, I haven't found another way to set the context of this importer:
 		*(unsafetools.FieldByName(conf.Importer, "ctxt").(**build.Context)) = buildCtx
 		pkgRaw, err = conf.Importer.Import(pkgPath)
@@ -164,6 +168,7 @@ func OpenDirectoryByPkgPath(
 			DirPath:    dirPath,
 			LookupPath: lookupPath,
 			Package:    pkgRaw,
+			FileSet:    directory.FileSet,
 			Files:      pkgFiles,
 		}
 
@@ -177,8 +182,21 @@ func OpenDirectoryByPkgPath(
 		}
 
 		if !onlyFiles {
-			info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
-			if _, err := conf.Check(dirPath, directory.FileSet, fileAsts, info); err != nil {
+			info := &types.Info{
+				Types:        make(map[ast.Expr]types.TypeAndValue),
+				Instances:    make(map[*ast.Ident]types.Instance),
+				Defs:         make(map[*ast.Ident]types.Object),
+				Uses:         make(map[*ast.Ident]types.Object),
+				Implicits:    make(map[ast.Node]types.Object),
+				Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+				Scopes:       make(map[ast.Node]*types.Scope),
+				FileVersions: make(map[*ast.File]string),
+			}
+			checkPath := pkgPath
+			if strings.HasSuffix(pkgName, `_test`) {
+				checkPath += `_test`
+			}
+			if _, err := conf.Check(checkPath, directory.FileSet, fileAsts, info); err != nil {
 				return nil, fmt.Errorf("unable to get package info: %w", err)
 			}
 			pkg.Info = info
@@ -0,0 +1,280 @@
+package gosrc
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DocComment is a parsed doc comment (or a line comment) of an entity.
+//
+// Directives (like "//go:generate") are not a part of the text,
+// see Directive.
+type DocComment struct {
+	Group    *ast.CommentGroup
+	Doc      *comment.Doc
+	Position token.Position
+
+	file *File
+}
+
+// newDocComment returns nil if there is no comment.
+func newDocComment(file *File, group *ast.CommentGroup) *DocComment {
+	if group == nil {
+		return nil
+	}
+	docComment := &DocComment{
+		Group:    group,
+		Position: file.Package.position(group.Pos()),
+		file:     file,
+	}
+	parser := &comment.Parser{
+		LookupPackage: file.lookupImportByName,
+		LookupSym:     file.Package.hasSymbol,
+	}
+	docComment.Doc = parser.Parse(group.Text())
+	return docComment
+}
+
+// lookupImportByName returns the path of the package imported in the file
+// with the specified name (used to resolve doc links like "[pkg.Type]").
+func (file *File) lookupImportByName(name string) (string, bool) {
+	for _, importSpec := range file.Ast.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := path.Base(importPath)
+		if importSpec.Name != nil {
+			importName = importSpec.Name.Name
+		}
+		if importName == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// hasSymbol returns true if the package has a package-level declaration
+// with the specified name, or if the type "recv" has a method or a field
+// with the specified name. It requires type information.
+func (pkg *Package) hasSymbol(recv, name string) bool {
+	if pkg == nil || pkg.Package == nil {
+		return false
+	}
+	if recv == "" {
+		return pkg.Package.Scope().Lookup(name) != nil
+	}
+	typeName, ok := pkg.Package.Scope().Lookup(recv).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(typeName.Type(), true, pkg.Package, name)
+	return obj != nil
+}
+
+// Text returns the text of the comment (without comment markers
+// and directives).
+func (docComment DocComment) Text() string {
+	return docComment.Group.Text()
+}
+
+// String just implements fmt.Stringer
+func (docComment DocComment) String() string {
+	return docComment.Text()
+}
+
+// Headings returns all the headings of the comment.
+func (docComment DocComment) Headings() []*comment.Heading {
+	var result []*comment.Heading
+	for _, block := range docComment.Doc.Content {
+		if heading, ok := block.(*comment.Heading); ok {
+			result = append(result, heading)
+		}
+	}
+	return result
+}
+
+// Lists returns all the lists of the comment.
+func (docComment DocComment) Lists() []*comment.List {
+	var result []*comment.List
+	for _, block := range docComment.Doc.Content {
+		if list, ok := block.(*comment.List); ok {
+			result = append(result, list)
+		}
+	}
+	return result
+}
+
+// CodeBlocks returns all the preformatted blocks of the comment.
+func (docComment DocComment) CodeBlocks() []*comment.Code {
+	var result []*comment.Code
+	for _, block := range docComment.Doc.Content {
+		if code, ok := block.(*comment.Code); ok {
+			result = append(result, code)
+		}
+	}
+	return result
+}
+
+// Links returns all the doc links of the comment (like "[pkg.Type]" or
+// "[Type.Method]"), in the order of appearance.
+func (docComment DocComment) Links() []*comment.DocLink {
+	var result []*comment.DocLink
+	var walkText func(texts []comment.Text)
+	walkText = func(texts []comment.Text) {
+		for _, text := range texts {
+			switch text := text.(type) {
+			case *comment.DocLink:
+				result = append(result, text)
+			case *comment.Link:
+				walkText(text.Text)
+			}
+		}
+	}
+	var walkBlocks func(blocks []comment.Block)
+	walkBlocks = func(blocks []comment.Block) {
+		for _, block := range blocks {
+			switch block := block.(type) {
+			case *comment.Paragraph:
+				walkText(block.Text)
+			case *comment.Heading:
+				walkText(block.Text)
+			case *comment.List:
+				for _, item := range block.Items {
+					walkBlocks(item.Content)
+				}
+			}
+		}
+	}
+	walkBlocks(docComment.Doc.Content)
+	return result
+}
+
+// ResolveLink returns the declaration referenced by the doc link (or nil
+// if it is not found in the index). Links without a package (like
+// "[Type]") are resolved in the package of the comment.
+func (docComment DocComment) ResolveLink(link *comment.DocLink, index *SymbolIndex) *Symbol {
+	importPath := link.ImportPath
+	if importPath == "" {
+		pkg := docComment.file.Package
+		if pkg == nil {
+			return nil
+		}
+		importPath = pkg.qualifiedPath()
+	}
+	name := link.Name
+	if link.Recv != "" {
+		name = link.Recv + "." + name
+	}
+	return index.Lookup(importPath + "." + name)
+}
+
+// GoComment returns the comment reformatted as a Go comment (with "// "
+// prefixes), for example to copy it into generated code.
+func (docComment DocComment) GoComment() string {
+	printer := &comment.Printer{}
+	text := strings.TrimSuffix(string(printer.Comment(docComment.Doc)), "\n")
+	var result strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			result.WriteString("//\n")
+			continue
+		}
+		result.WriteString("// " + line + "\n")
+	}
+	return result.String()
+}
+
+// Markdown returns the comment formatted in Markdown. Doc links are
+// rendered as links to pkg.go.dev.
+func (docComment DocComment) Markdown() string {
+	printer := &comment.Printer{}
+	return string(printer.Markdown(docComment.Doc))
+}
+
+// HTML returns the comment formatted in HTML. Doc links are rendered
+// as links to pkg.go.dev.
+func (docComment DocComment) HTML() string {
+	printer := &comment.Printer{}
+	return string(printer.HTML(docComment.Doc))
+}
+
+// DocComment returns the doc comment of the package (of the first file which
+// has it), or nil if there is no doc comment.
+func (pkg *Package) DocComment() *DocComment {
+	for _, file := range pkg.Files {
+		if file.Package != pkg || file.Ast.Doc == nil {
+			continue
+		}
+		return newDocComment(file, file.Ast.Doc)
+	}
+	return nil
+}
+
+// DocComment returns the doc comment of the type definition (or nil if there is
+// no doc comment). For a non-grouped declaration it is the comment
+// of the declaration ("// T is ...\ntype T ...").
+func (astTypeSpec AstTypeSpec) DocComment() *DocComment {
+	doc := astTypeSpec.TypeSpec.Doc
+	if doc == nil {
+		if genDecl := astTypeSpec.File.genDeclOf(astTypeSpec.TypeSpec); genDecl != nil && len(genDecl.Specs) == 1 {
+			doc = genDecl.Doc
+		}
+	}
+	return newDocComment(astTypeSpec.File, doc)
+}
+
+// LineComment returns the comment following the type definition on the same
+// line (or nil if there is no such comment).
+func (astTypeSpec AstTypeSpec) LineComment() *DocComment {
+	return newDocComment(astTypeSpec.File, astTypeSpec.TypeSpec.Comment)
+}
+
+// DocComment returns the doc comment of the field (or nil if there is none).
+func (field Field) DocComment() *DocComment {
+	return newDocComment(field.Struct.File, field.Field.Doc)
+}
+
+// LineComment returns the comment following the field on the same line
+// (or nil if there is no such comment).
+func (field Field) LineComment() *DocComment {
+	return newDocComment(field.Struct.File, field.Field.Comment)
+}
+
+// DocComment returns the doc comment of the function (or nil if there is none).
+// Functions never have line comments.
+func (fn Func) DocComment() *DocComment {
+	return newDocComment(fn.File, fn.FuncDecl.Doc)
+}
+
+// DocComment returns the doc comment of the method (or nil if there is none).
+func (method InterfaceMethod) DocComment() *DocComment {
+	return newDocComment(method.Interface.File, method.Field.Doc)
+}
+
+// LineComment returns the comment following the method on the same line
+// (or nil if there is no such comment).
+func (method InterfaceMethod) LineComment() *DocComment {
+	return newDocComment(method.Interface.File, method.Field.Comment)
+}
+
+// DocComment returns the doc comment of the constants (or nil if there is none).
+// For a non-grouped declaration it is the comment of the declaration.
+func (_const Const) DocComment() *DocComment {
+	doc := _const.ValueSpec.Doc
+	if doc == nil && len(_const.GenDecl.Specs) == 1 {
+		doc = _const.GenDecl.Doc
+	}
+	return newDocComment(_const.File, doc)
+}
+
+// LineComment returns the comment following the constants on the same
+// line (or nil if there is no such comment).
+func (_const Const) LineComment() *DocComment {
+	return newDocComment(_const.File, _const.ValueSpec.Comment)
+}
@@ -0,0 +1,59 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestDocComment(t *testing.T) {
+	pkg := openTestPackage(t, "doccomment")
+	require.Equal(t, "Package doccomment is used to test parsing of doc comments.\n", pkg.DocComment().Text())
+
+	config := findStruct(t, pkg, "Config")
+	doc := config.DocComment()
+	require.NotNil(t, doc)
+	require.Equal(t, 8, doc.Position.Line)
+	require.NotContains(t, doc.Text(), "gen:builder")
+	require.Len(t, doc.Headings(), 1)
+	require.Len(t, doc.Lists(), 1)
+	require.Len(t, doc.Lists()[0].Items, 2)
+	require.Len(t, doc.CodeBlocks(), 1)
+	require.Equal(t, "cfg := New()\ncfg.Validate()\n", doc.CodeBlocks()[0].Text)
+	require.Contains(t, doc.GoComment(), "// # Usage\n")
+	require.Contains(t, doc.Markdown(), "### Usage")
+	require.Nil(t, config.LineComment())
+
+	links := doc.Links()
+	require.Len(t, links, 5)
+	require.Equal(t, "strings", links[2].ImportPath)
+	require.Equal(t, "Builder", links[2].Name)
+
+	index := gosrc.NewSymbolIndex(gosrc.Packages{pkg})
+	symbol := doc.ResolveLink(links[0], index)
+	require.NotNil(t, symbol)
+	require.Equal(t, gosrc.SymbolKindMethod, symbol.Kind)
+	require.Equal(t, "Validate", symbol.Name())
+	symbol = doc.ResolveLink(links[1], index)
+	require.NotNil(t, symbol)
+	require.Equal(t, gosrc.SymbolKindField, symbol.Kind)
+	require.Nil(t, doc.ResolveLink(links[2], index))
+	require.Equal(t, gosrc.SymbolKindFunc, doc.ResolveLink(links[3], index).Kind)
+
+	fields, err := config.Fields()
+	require.NoError(t, err)
+	require.Equal(t, "Name is the name.\n", fields[0].DocComment().Text())
+	require.Equal(t, "required\n", fields[0].LineComment().Text())
+	require.Nil(t, fields[1].DocComment())
+
+	newFn := pkg.AllFuncs().FindByName("New")[0]
+	newLinks := newFn.DocComment().Links()
+	require.Len(t, newLinks, 1)
+	require.Equal(t, gosrc.SymbolKindType, newFn.DocComment().ResolveLink(newLinks[0], index).Kind)
+
+	consts := pkg.Consts()
+	require.Equal(t, "MaxLen is the maximal length.\n", consts.FindByName("MaxLen").DocComment().Text())
+	require.Nil(t, consts.FindByName("MinLen").DocComment())
+	require.Equal(t, "the minimal length\n", consts.FindByName("MinLen").LineComment().Text())
+}
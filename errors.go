@@ -2,6 +2,8 @@ package gosrc
 
 import (
 	"fmt"
+	"go/token"
+	"strings"
 )
 
 // ErrPackageNotFound is returned when was unable to perform an operation
@@ -16,3 +18,134 @@ func (err ErrPackageNotFound) Error() string {
 	return fmt.Sprintf("unable to find package with path '%s' in %s",
 		err.GoPath, err.LookupPaths)
 }
+
+// ErrImportCycle is returned when was unable to perform an operation
+// due to a cycle of imports of packages.
+type ErrImportCycle struct {
+	// Cycle is the paths of the packages of the cycle.
+	Cycle []string
+}
+
+// Error implements error
+func (err ErrImportCycle) Error() string {
+	return fmt.Sprintf("import cycle: %s", strings.Join(err.Cycle, " -> "))
+}
+
+// ErrMalformedDirective is returned when was unable to parse (or to
+// unmarshal) a directive comment, see Directive.
+type ErrMalformedDirective struct {
+	Position token.Position
+	Text     string
+	Err      error
+}
+
+// Error implements error
+func (err ErrMalformedDirective) Error() string {
+	return fmt.Sprintf("%s: malformed directive <%s>: %v", err.Position, err.Text, err.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (err ErrMalformedDirective) Unwrap() error {
+	return err.Err
+}
+
+// ErrMalformedDirectives is returned when multiple directive comments
+// are malformed.
+type ErrMalformedDirectives []ErrMalformedDirective
+
+// Error implements error
+func (errs ErrMalformedDirectives) Error() string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+	return strings.Join(result, "; ")
+}
+
+// ErrMalformedTag is returned when was unable to parse a struct field tag.
+type ErrMalformedTag struct {
+	Position token.Position
+	Tag      string
+	Err      error
+}
+
+// Error implements error
+func (err ErrMalformedTag) Error() string {
+	return fmt.Sprintf("%s: malformed struct field tag <%s>: %v", err.Position, err.Tag, err.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (err ErrMalformedTag) Unwrap() error {
+	return err.Err
+}
+
+// ErrMalformedTags is returned when multiple struct field tags
+// are malformed.
+type ErrMalformedTags []ErrMalformedTag
+
+// Error implements error
+func (errs ErrMalformedTags) Error() string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+	return strings.Join(result, "; ")
+}
+
+// ErrInvalidGeneratedCode is returned when was unable to format
+// a generated source code (usually because of a syntax error).
+type ErrInvalidGeneratedCode struct {
+	// Source is the unformatted source code.
+	Source []byte
+	Err    error
+}
+
+// Error implements error
+func (err ErrInvalidGeneratedCode) Error() string {
+	return fmt.Sprintf("invalid generated code: %v", err.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (err ErrInvalidGeneratedCode) Unwrap() error {
+	return err.Err
+}
+
+// ErrInvalidEdit is returned when was unable to format a modified
+// source code file (usually because the modification introduced
+// a syntax error). Position is the beginning of the modified
+// declaration in the original file.
+type ErrInvalidEdit struct {
+	Position token.Position
+
+	// Source is the unformatted modified source code.
+	Source []byte
+	Err    error
+}
+
+// Error implements error
+func (err ErrInvalidEdit) Error() string {
+	return fmt.Sprintf("%s: unable to format the modified code: %v", err.Position, err.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (err ErrInvalidEdit) Unwrap() error {
+	return err.Err
+}
+
+// ErrTemplate is returned when was unable to parse or to execute
+// a template. Position points into the template.
+type ErrTemplate struct {
+	Position token.Position
+	Message  string
+	Err      error
+}
+
+// Error implements error
+func (err ErrTemplate) Error() string {
+	return fmt.Sprintf("%s: %s", err.Position, err.Message)
+}
+
+// Unwrap returns the reason of the error.
+func (err ErrTemplate) Unwrap() error {
+	return err.Err
+}
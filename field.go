@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"go/ast"
 	"go/types"
-	"strings"
 
 	"github.com/fatih/structtag"
 )
@@ -17,6 +16,8 @@ type Field struct {
 	Names     []*ast.Ident
 	Index     uint
 	TypeValue types.TypeAndValue
+
+	tags *fieldTags
 }
 
 // Field is a set of Field-s.
@@ -24,23 +25,48 @@ type Fields []*Field
 
 // IsPointer returns true if the field has a pointer value
 func (field Field) IsPointer() bool {
-	_, ok := TypeDeepest(field.TypeValue.Type).(*types.Pointer)
+	_, ok := field.underlying().(*types.Pointer)
 	return ok
 }
 
 // IsSlice returns true if the field has a slice value
 func (field Field) IsSlice() bool {
-	_, ok := TypeDeepest(field.TypeValue.Type).(*types.Slice)
+	_, ok := field.underlying().(*types.Slice)
 	return ok
 }
 
-// Methods returns all methods of the type of the value of the field.
-func (field Field) Methods() Funcs {
-	namedType, ok := field.TypeValue.Type.(*types.Named)
-	if !ok {
-		return nil
+// IsTypeParam returns true if the type of the field is a type parameter
+// of the generic structure (for example, field "Value T" of "Box[T any]").
+func (field Field) IsTypeParam() bool {
+	_, ok := field.TypeValue.Type.(*types.TypeParam)
+	return ok
+}
+
+// TypeParams returns the type parameters of the structure, which are
+// referenced by the type of the field (for example, for field
+// "Items map[K][]V" it returns K and V).
+func (field Field) TypeParams() TypeParams {
+	var result TypeParams
+	structTypeParams := field.Struct.TypeParams()
+	for _, typeParam := range typeParamsOf(field.TypeValue.Type) {
+		found := structTypeParams.FindByName(typeParam.Obj().Name())
+		if found == nil || result.FindByName(found.Name()) != nil {
+			continue
+		}
+		result = append(result, found)
 	}
-	return field.Struct.File.Package.Funcs().FindMethodsOf(namedType.Obj().Name())
+	return result
+}
+
+// Methods returns all methods of the type of the value of the field,
+// which source code is available in the package of the structure
+// (including methods promoted through embedded fields).
+//
+// See also MethodSet.
+func (field Field) Methods() Funcs {
+	// no errors without a loader
+	methods, _ := field.MethodSet(nil)
+	return methods.Funcs()
 }
 
 // MethodByName returns the method of the type of the value of the field by
@@ -64,12 +90,44 @@ func (field Field) TypeElem() types.Type {
 	return TypeElem(field.TypeValue.Type)
 }
 
-// TagGet returns a value of the struct field tag with the specified key.
+// TagGet returns a value of the struct field tag with the specified key
+// (the first one if the key is repeated, like reflect.StructTag.Get does).
+// It returns false if the tag is malformed, use LookupTag to get the error.
+//
+// The tag is parsed once, when the field is created by Struct.Fields.
 func (field Field) TagGet(key string) (string, bool) {
-	if field.Tag == nil {
-		return "", false
+	value, ok := field.parsedTags().values[key]
+	return value, ok
+}
+
+// IsEmbedded returns true if the field has no name (like "io.Reader"
+// in "struct{ io.Reader }").
+func (field Field) IsEmbedded() bool {
+	return len(field.Names) == 0
+}
+
+// tagValues returns the values of the tag by the keys (the first one for
+// a repeated key), or nil if the tag is malformed.
+func tagValues(rawTag string) map[string]string {
+	tags, err := structtag.Parse(rawTag)
+	if err != nil {
+		return nil
+	}
+	result := map[string]string{}
+	if tags == nil {
+		// only spaces
+		return result
 	}
-	tags, err := structtag.Parse(strings.Trim(field.Tag.Value, "`"))
+	for _, tag := range tags.Tags() {
+		if _, ok := result[tag.Key]; !ok {
+			result[tag.Key] = tag.Value()
+		}
+	}
+	return result
+}
+
+func tagGet(rawTag string, key string) (string, bool) {
+	tags, err := structtag.Parse(rawTag)
 	if err != nil {
 		return "", false
 	}
@@ -80,6 +138,33 @@ func (field Field) TagGet(key string) (string, bool) {
 	return tag.Value(), true
 }
 
+// FindByName returns the field with the specified name (see Field.Name),
+// or nil if there is no such field.
+func (fields Fields) FindByName(name string) *Field {
+	for _, field := range fields {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// findByFieldIndex returns the field which declares the types.Struct field
+// with the specified index (a field like "A, B int" declares two of them).
+func (fields Fields) findByFieldIndex(typesFieldIdx int) *Field {
+	for _, field := range fields {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		if typesFieldIdx < count {
+			return field
+		}
+		typesFieldIdx -= count
+	}
+	return nil
+}
+
 // TypeStdSize returns the size (in bytes) of the value for specified
 // word size and max alignment. See more details in types.StdSizes.
 func (field Field) TypeStdSize(wordSize, maxAlign int64) int64 {
@@ -97,7 +182,106 @@ func (field Field) Name() string {
 	}
 
 	// The field has no name, so we use Type name as the field name.
-	return itemTypeName(field.TypeValue.Type).Name
+	if name := embeddedFieldName(field.Type); name != "" {
+		return name
+	}
+	return field.ItemTypeName().Name
+}
+
+// embeddedFieldName returns the name of an embedded field by its type
+// expression (like "Meta" for "*other.Meta" or "List" for "List[T]").
+func embeddedFieldName(expr ast.Expr) string {
+	ident := embeddedFieldIdent(expr)
+	if ident == nil {
+		return ""
+	}
+	return ident.Name
+}
+
+// embeddedFieldIdent returns the identifier of the type name of
+// an embedded field by its type expression.
+func embeddedFieldIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch casted := expr.(type) {
+		case *ast.StarExpr:
+			expr = casted.X
+		case *ast.ParenExpr:
+			expr = casted.X
+		case *ast.IndexExpr:
+			expr = casted.X
+		case *ast.IndexListExpr:
+			expr = casted.X
+		case *ast.SelectorExpr:
+			return casted.Sel
+		case *ast.Ident:
+			return casted
+		default:
+			return nil
+		}
+	}
+}
+
+// inlineTypeExpr returns the type expression of items referenced by
+// the field (walking through pointers, slices, arrays, channels and values
+// of maps), like "struct{ A int }" for "[]*struct{ A int }".
+func (field Field) inlineTypeExpr() ast.Expr {
+	expr := field.Type
+	for {
+		switch casted := expr.(type) {
+		case *ast.StarExpr:
+			expr = casted.X
+		case *ast.ParenExpr:
+			expr = casted.X
+		case *ast.ArrayType:
+			expr = casted.Elt
+		case *ast.MapType:
+			expr = casted.Value
+		case *ast.ChanType:
+			expr = casted.Value
+		default:
+			return expr
+		}
+	}
+}
+
+// InlineStruct returns the anonymous structure declared in the type
+// of the field (like "Opts struct{ A int }" or "Items []struct{ A int }"),
+// or nil if there is no such structure.
+func (field *Field) InlineStruct() *Struct {
+	structType, ok := field.inlineTypeExpr().(*ast.StructType)
+	if !ok || structType.Incomplete {
+		return nil
+	}
+	return &Struct{
+		AstTypeSpec: AstTypeSpec{
+			File:        field.Struct.File,
+			TypeSpec:    &ast.TypeSpec{Type: structType},
+			ParentField: field,
+		},
+	}
+}
+
+// InlineInterface returns the anonymous interface declared in the type
+// of the field (like "Logger interface{ Printf(string, ...any) }"),
+// or nil if there is no such interface.
+func (field *Field) InlineInterface() *Interface {
+	interfaceType, ok := field.inlineTypeExpr().(*ast.InterfaceType)
+	if !ok {
+		return nil
+	}
+	return &Interface{
+		AstTypeSpec: AstTypeSpec{
+			File:        field.Struct.File,
+			TypeSpec:    &ast.TypeSpec{Type: interfaceType},
+			ParentField: field,
+		},
+	}
+}
+
+// TypeRef returns the structured reference to the type of the value
+// of the field.
+func (field Field) TypeRef() *TypeRef {
+	return NewTypeRef(field.TypeValue.Type)
 }
 
 // ItemTypeName returns the name of the value type of an item referenced
@@ -105,10 +289,14 @@ func (field Field) Name() string {
 // * If field value type is uint64, then the returned value will be 'uint64'.
 // * If field value type is []uint64, then the returned value will be 'uint64'.
 // * If field value type is *uint64, then the returned value will be 'uint64'.
+// * If field value type is map[string]*pkg.Value, then the returned
+// value will be 'Value' (with path 'pkg').
 //
 // This could be useful to find dependencies by names.
+//
+// See also TypeRef.
 func (field Field) ItemTypeName() TypeNameValue {
-	return itemTypeName(field.TypeValue.Type)
+	return field.TypeRef().Item().TypeNameValue()
 }
 
 // TypeNameValue is just a combination of a value type name and of a path
@@ -118,40 +306,55 @@ type TypeNameValue struct {
 	Path string
 }
 
-func itemTypeName(typ types.Type) TypeNameValue {
-	for {
-		modified := true
-		switch casted := typ.(type) {
-		case interface{ Elem() types.Type }:
-			typ = casted.Elem()
-		case *types.Pointer:
-			typ = casted.Underlying()
-		default:
-			modified = false
-		}
-		if !modified {
-			break
-		}
-	}
-	// typ.String() is presented in forms "my/path/MyType" and "my/path.MyType"
-	parts := strings.Split(typ.String(), "/")
-	// lastPart is presented in forms "MyType" and "path.MyType"
-	lastPart := parts[len(parts)-1]
-	// subParts either ["MyType"] or ["path", "MyType"]
-	subParts := strings.Split(lastPart, ".")
-	switch len(subParts) {
-	case 1:
-		return TypeNameValue{
-			Name: subParts[0],
-			Path: "",
-		}
-	case 2:
-		parts[len(parts)-1] = subParts[0]
-		return TypeNameValue{
-			Name: subParts[1],
-			Path: strings.Join(parts, "/"),
-		}
-	default:
-		panic(fmt.Sprintf("do not know how to parse '%s'", typ.String()))
+// underlying returns the underlying type of the value of the field (or nil
+// if there is no type information).
+func (field Field) underlying() types.Type {
+	if field.TypeValue.Type == nil {
+		return nil
 	}
+	return TypeDeepest(field.TypeValue.Type)
+}
+
+// IsMap returns true if the field has a map value.
+func (field Field) IsMap() bool {
+	_, ok := field.underlying().(*types.Map)
+	return ok
+}
+
+// IsChan returns true if the field has a channel value.
+func (field Field) IsChan() bool {
+	_, ok := field.underlying().(*types.Chan)
+	return ok
+}
+
+// IsFunc returns true if the field has a function value.
+func (field Field) IsFunc() bool {
+	_, ok := field.underlying().(*types.Signature)
+	return ok
+}
+
+// IsInterface returns true if the field has an interface value (including
+// type parameters, since they are constrained by interfaces).
+func (field Field) IsInterface() bool {
+	_, ok := field.underlying().(*types.Interface)
+	return ok
+}
+
+// IsBasic returns true if the field has a value of a predeclared type
+// (like int, string or unsafe.Pointer), or of a type defined on top of it.
+func (field Field) IsBasic() bool {
+	_, ok := field.underlying().(*types.Basic)
+	return ok
+}
+
+// IsArray returns true if the field has an array value.
+func (field Field) IsArray() bool {
+	_, ok := field.underlying().(*types.Array)
+	return ok
+}
+
+// IsStruct returns true if the field has a structure value.
+func (field Field) IsStruct() bool {
+	_, ok := field.underlying().(*types.Struct)
+	return ok
 }
@@ -6,6 +6,7 @@ import (
 	"go/parser"
 	"go/token"
 	"go/types"
+	"io/ioutil"
 	"path"
 	"regexp"
 	"strconv"
@@ -20,21 +21,41 @@ type File struct {
 	Path    string
 	Package *Package
 	Ast     *ast.File
+	FileSet *token.FileSet
+
+	// src is the content of the file at the moment of parsing.
+	src []byte
+
+	// funcs are all the functions of the file (created once, so
+	// the same *Func is returned by every call of Funcs and AllFuncs).
+	funcs Funcs
 }
 
 // Files is a set of File-s.
 type Files []*File
 
 func newFile(fileSet *token.FileSet, path string) (*File, error) {
-	parsedFile, err := parser.ParseFile(fileSet, path, nil, parser.ParseComments)
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read go file '%s': %w", path, err)
+	}
+	parsedFile, err := parser.ParseFile(fileSet, path, src, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse go file '%s': %w", path, err)
 	}
 
-	return &File{
-		Path: path,
-		Ast:  parsedFile,
-	}, nil
+	file := &File{
+		Path:    path,
+		Ast:     parsedFile,
+		FileSet: fileSet,
+		src:     src,
+	}
+	for _, decl := range parsedFile.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			file.funcs = append(file.funcs, newFunc(file, funcDecl))
+		}
+	}
+	return file, nil
 }
 
 // IsPassBuildTags returns true if file satisfies specified build tags.
@@ -119,22 +140,41 @@ func (file *File) ToType(expr ast.Expr) (types.TypeAndValue, error) {
 	return file.Package.ToType(expr)
 }
 
+// typeParamOf returns the type-checked type parameter defined by
+// the identifier (or nil if there is no type information).
+func (file *File) typeParamOf(ident *ast.Ident) *types.TypeParam {
+	if file == nil || file.Package == nil || file.Package.Info == nil {
+		return nil
+	}
+	obj := file.Package.Info.Defs[ident]
+	if obj == nil {
+		return nil
+	}
+	typeParam, _ := obj.Type().(*types.TypeParam)
+	return typeParam
+}
+
 // Funcs returns all functions defined in the file.
+//
+// Only methods are returned, see AllFuncs for functions without
+// a receiver.
 func (file *File) Funcs() Funcs {
 	var funcs Funcs
-	for _, decl := range file.Ast.Decls {
-		funcDecl, ok := decl.(*ast.FuncDecl)
-		if !ok {
-			continue
-		}
-		if funcDecl.Recv == nil {
+	for _, fn := range file.funcs {
+		if fn.FuncDecl.Recv == nil {
 			continue
 		}
-		funcs = append(funcs, newFunc(funcDecl))
+		funcs = append(funcs, fn)
 	}
 	return funcs
 }
 
+// AllFuncs returns all functions defined in the file: both methods
+// and functions without a receiver.
+func (file *File) AllFuncs() Funcs {
+	return append(Funcs(nil), file.funcs...)
+}
+
 // Structs returns all structures defined in the file.
 func (file *File) Structs() Structs {
 	return file.structsWithMagicComment(nil)
@@ -163,6 +203,23 @@ func (file *File) structsWithMagicComment(magicComment *string) Structs {
 	return structs
 }
 
+// Interfaces returns all interfaces defined in the file.
+func (file *File) Interfaces() Interfaces {
+	var interfaces Interfaces
+	file.findTypes(nil, func(typeSpec *ast.TypeSpec) {
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok {
+			return
+		}
+		interfaces = append(interfaces, &Interface{
+			AstTypeSpec: AstTypeSpec{
+				File:     file,
+				TypeSpec: typeSpec,
+			},
+		})
+	})
+	return interfaces
+}
+
 // AstTypeSpecs returns all type definitions.
 func (file *File) AstTypeSpecs() AstTypeSpecs {
 	var astTypeSpecs AstTypeSpecs
@@ -187,27 +244,18 @@ func (file *File) findTypes(magicComment *string, appendFn func(typeSpec *ast.Ty
 			continue
 		}
 
-		if expectedComment != "" {
-			if genDecl.Doc == nil {
-				continue
-			}
-			shouldAdd := false
-			for _, list := range genDecl.Doc.List {
-				if list.Text == expectedComment {
-					shouldAdd = true
-					break
-				}
-			}
-			if !shouldAdd {
-				continue
-			}
-		}
-
 		for _, spec := range genDecl.Specs {
 			typeSpec, ok := spec.(*ast.TypeSpec)
 			if !ok {
 				continue
 			}
+			// the magic comment could be either on the whole declaration
+			// or on a type inside a grouped declaration ("type ( ... )")
+			if expectedComment != "" &&
+				!hasCommentLine(genDecl.Doc, expectedComment) &&
+				!hasCommentLine(typeSpec.Doc, expectedComment) {
+				continue
+			}
 			if typeSpec.Doc == nil {
 				typeSpec.Doc = genDecl.Doc
 			}
@@ -218,6 +266,35 @@ func (file *File) findTypes(magicComment *string, appendFn func(typeSpec *ast.Ty
 	return
 }
 
+func hasCommentLine(commentGroup *ast.CommentGroup, text string) bool {
+	if commentGroup == nil {
+		return false
+	}
+	for _, comment := range commentGroup.List {
+		if comment.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// genDeclOf returns the declaration containing the specification (or nil
+// if there is no such declaration in the file).
+func (file *File) genDeclOf(spec ast.Spec) *ast.GenDecl {
+	for _, decl := range file.Ast.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, candidate := range genDecl.Specs {
+			if candidate == spec {
+				return genDecl
+			}
+		}
+	}
+	return nil
+}
+
 // IsTest returns true if the source code file is of unit-tests.
 func (file File) IsTest() bool {
 	return strings.HasSuffix(file.Path, `_test.go`)
@@ -250,6 +327,17 @@ func (files Files) FindByPath(path string) *File {
 	return nil
 }
 
+// findByPos finds a file which contains the specified position (if there's
+// one), otherwise returns nil.
+func (files Files) findByPos(pos token.Pos) *File {
+	for _, file := range files {
+		if file.Ast.FileStart <= pos && pos <= file.Ast.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
 // FilterByBuildTags returns files which satisfies specified build tags.
 //
 // See also https://golang.org/cmd/go/#hdr-Build_constraints
@@ -0,0 +1,186 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileEdit is a modification of a source code file. The file on the disk
+// is not changed until Write is called.
+type FileEdit struct {
+	File *File
+
+	// Original is the content of the file at the moment of parsing.
+	Original []byte
+
+	// Modified is the new content of the file.
+	Modified []byte
+}
+
+// IsChanged returns true if the modified content differs from
+// the original one.
+func (edit FileEdit) IsChanged() bool {
+	return !bytes.Equal(edit.Original, edit.Modified)
+}
+
+// Diff returns the modification in the unified diff format (or an empty
+// string if there are no changes).
+func (edit FileEdit) Diff() string {
+	aName, bName := edit.File.Path, edit.File.Path
+	if !filepath.IsAbs(edit.File.Path) {
+		aName, bName = "a/"+aName, "b/"+bName
+	}
+	return unifiedDiff(aName, bName, edit.Original, edit.Modified)
+}
+
+// Write writes the modified content to the file (in place). It returns
+// an error if the file was changed on the disk after it was parsed.
+func (edit FileEdit) Write() error {
+	stat, err := os.Stat(edit.File.Path)
+	if err != nil {
+		return fmt.Errorf("unable to stat file '%s': %w", edit.File.Path, err)
+	}
+	current, err := ioutil.ReadFile(edit.File.Path)
+	if err != nil {
+		return fmt.Errorf("unable to read file '%s': %w", edit.File.Path, err)
+	}
+	if !bytes.Equal(current, edit.Original) {
+		return fmt.Errorf("file '%s' was changed after it was parsed", edit.File.Path)
+	}
+	if !edit.IsChanged() {
+		return nil
+	}
+	err = ioutil.WriteFile(edit.File.Path, edit.Modified, stat.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("unable to write file '%s': %w", edit.File.Path, err)
+	}
+	return nil
+}
+
+// textEdit is a replacement of the range of the original text.
+type textEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// sourceChunk is a range of offsets in a source code file.
+type sourceChunk struct {
+	start int
+	end   int
+}
+
+// applyTextEdits applies the edits to the source code of the file and
+// formats the modified top-level declarations (the rest of the file is
+// kept as is, even if it is not formatted with gofmt). It returns
+// an error if some edits overlap.
+func (file *File) applyTextEdits(edits []textEdit) ([]byte, error) {
+	tokenFile := file.FileSet.File(file.Ast.Pos())
+	edits = append([]textEdit{}, edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	// ranges (of the original offsets) to be formatted: the top-level
+	// declarations containing the edits, or the edits themselves
+	var ranges []sourceChunk
+	for _, edit := range edits {
+		editRange := sourceChunk{start: edit.start, end: edit.end}
+		for _, decl := range file.Ast.Decls {
+			declRange := file.declChunk(decl)
+			if declRange.start <= edit.start && edit.end <= declRange.end {
+				editRange = declRange
+				break
+			}
+		}
+		if len(ranges) > 0 && editRange.start <= ranges[len(ranges)-1].end {
+			last := &ranges[len(ranges)-1]
+			if editRange.end > last.end {
+				last.end = editRange.end
+			}
+			continue
+		}
+		ranges = append(ranges, editRange)
+	}
+
+	var result bytes.Buffer
+	prevEnd := 0
+	for _, edit := range edits {
+		if edit.start < prevEnd {
+			return nil, fmt.Errorf("conflicting modifications at %s", tokenFile.Position(tokenFile.Pos(edit.start)))
+		}
+		result.Write(file.src[prevEnd:edit.start])
+		result.WriteString(edit.text)
+		prevEnd = edit.end
+	}
+	result.Write(file.src[prevEnd:])
+	modified := result.Bytes()
+
+	// converting the ranges to the offsets of the modified source code
+	// (the edits are inside the ranges)
+	originalStarts := make([]int, len(ranges))
+	for idx := range ranges {
+		originalStarts[idx] = ranges[idx].start
+		startShift, endShift := 0, 0
+		for _, edit := range edits {
+			shift := len(edit.text) - (edit.end - edit.start)
+			if edit.start < ranges[idx].start {
+				startShift += shift
+			}
+			if edit.start <= ranges[idx].end {
+				endShift += shift
+			}
+		}
+		ranges[idx].start += startShift
+		ranges[idx].end += endShift
+	}
+
+	var formatted bytes.Buffer
+	prevEnd = 0
+	for idx, modifiedRange := range ranges {
+		formatted.Write(modified[prevEnd:modifiedRange.start])
+		source := modified[modifiedRange.start:modifiedRange.end]
+		if len(bytes.TrimSpace(source)) > 0 {
+			var err error
+			source, err = format.Source(source)
+			if err != nil {
+				return nil, ErrInvalidEdit{
+					Position: tokenFile.Position(tokenFile.Pos(originalStarts[idx])),
+					Source:   modified,
+					Err:      err,
+				}
+			}
+		}
+		formatted.Write(source)
+		prevEnd = modifiedRange.end
+	}
+	formatted.Write(modified[prevEnd:])
+	return formatted.Bytes(), nil
+}
+
+// declChunk returns the range of the top-level declaration including its
+// doc comment.
+func (file *File) declChunk(decl ast.Decl) sourceChunk {
+	start := decl.Pos()
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	case *ast.FuncDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	}
+	tokenFile := file.FileSet.File(decl.Pos())
+	return sourceChunk{
+		start: tokenFile.Offset(start),
+		end:   tokenFile.Offset(decl.End()),
+	}
+}
@@ -0,0 +1,315 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// FlatFieldStatus defines if a field could be selected by its name
+// from the structure (see FlatField).
+type FlatFieldStatus uint
+
+const (
+	// FlatFieldStatusAccessible means the field could be selected
+	// by its name.
+	FlatFieldStatusAccessible = FlatFieldStatus(iota)
+
+	// FlatFieldStatusShadowed means there is a field with the same
+	// name at a shallower depth.
+	FlatFieldStatusShadowed
+
+	// FlatFieldStatusAmbiguous means there are multiple fields with
+	// the same name at the shallowest depth, so none of them could
+	// be selected by the name.
+	FlatFieldStatusAmbiguous
+)
+
+// String just implements fmt.Stringer
+func (status FlatFieldStatus) String() string {
+	switch status {
+	case FlatFieldStatusAccessible:
+		return "accessible"
+	case FlatFieldStatusShadowed:
+		return "shadowed"
+	case FlatFieldStatusAmbiguous:
+		return "ambiguous"
+	default:
+		return fmt.Sprintf("unknown_status_%d", uint(status))
+	}
+}
+
+// FlatField represents one field of a structure, either declared directly
+// in the structure or promoted through embedded structures.
+type FlatField struct {
+	// Var is the field itself.
+	Var *types.Var
+
+	// Tag is the tag of the field (without backquotes).
+	Tag string
+
+	// Path is the chain of fields to access the field, starting
+	// with a field of the root structure and finishing with Var.
+	Path []*types.Var
+
+	// PathTags are tags of fields of Path (in the same order, the last
+	// item is equals to Tag). It could be used to find out if a field
+	// is inherited by an encoder (for example, encoding/json does not
+	// promote fields of an embedded structure if it has a name in
+	// the "json" tag).
+	PathTags []string
+
+	// Index is the index sequence to access the field through
+	// reflect.Value.FieldByIndex.
+	Index []int
+
+	// Field is the source code representation of the field. It is set
+	// only for fields declared directly in the root structure.
+	Field *Field
+
+	Status FlatFieldStatus
+}
+
+// FlatFields is a set of FlatField-s.
+type FlatFields []*FlatField
+
+// Name returns the name of the field.
+func (field FlatField) Name() string {
+	return field.Var.Name()
+}
+
+// Depth returns the embedding depth of the field (zero for the fields
+// declared directly in the root structure).
+func (field FlatField) Depth() int {
+	return len(field.Path) - 1
+}
+
+// IsPromoted returns true if the field is promoted through an embedded
+// field.
+func (field FlatField) IsPromoted() bool {
+	return field.Depth() > 0
+}
+
+// AccessPath returns the full selector path to the field, for
+// example "Base.Meta.ID".
+func (field FlatField) AccessPath() string {
+	var names []string
+	for _, v := range field.Path {
+		names = append(names, v.Name())
+	}
+	return strings.Join(names, ".")
+}
+
+// TagGet returns a value of the struct field tag with the specified key.
+func (field FlatField) TagGet(key string) (string, bool) {
+	return tagGet(field.Tag, key)
+}
+
+// IsInheritedBy returns true if each embedded field of the path has no
+// name in the tag with the specified key (or has option "inline"). This is
+// a common convention of encoders (like encoding/json or yaml) to
+// define if the fields of an embedded structure are promoted.
+func (field FlatField) IsInheritedBy(tagKey string) bool {
+	for _, tag := range field.PathTags[:len(field.PathTags)-1] {
+		value, ok := tagGet(tag, tagKey)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(value, ",")
+		if parts[0] == "" {
+			continue
+		}
+		inline := false
+		for _, option := range parts[1:] {
+			if option == "inline" {
+				inline = true
+				break
+			}
+		}
+		if !inline {
+			return false
+		}
+	}
+	return true
+}
+
+// String just implements fmt.Stringer
+func (field FlatField) String() string {
+	return fmt.Sprintf("%s %s (%s)", field.AccessPath(), field.Var.Type(), field.Status)
+}
+
+// FlatFields returns all the fields of the structure including the fields
+// promoted through embedded structures (recursively, through pointers and
+// from other packages). Shadowed and ambiguous fields are also returned,
+// see FlatField.Status and FlatFields.Accessible.
+//
+// Requires type information (the package should not be opened with
+// "onlyFiles").
+func (_struct *Struct) FlatFields() (FlatFields, error) {
+	typ := _struct.Type()
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for %s", _struct)
+	}
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct, but %T", typ, typ.Underlying())
+	}
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+
+	result := flattenStruct(typ, structType)
+	for _, flatField := range result {
+		if flatField.Depth() != 0 {
+			continue
+		}
+		flatField.Field = fields.findByFieldIndex(flatField.Index[0])
+	}
+	return result, nil
+}
+
+// FlattenStruct returns all the fields of the structure including the fields
+// promoted through embedded structures. It is the same as Struct.FlatFields,
+// but works with any types.Type with a structure as the underlying type.
+func FlattenStruct(typ types.Type) (FlatFields, error) {
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct, but %T", typ, typ.Underlying())
+	}
+	return flattenStruct(typ, structType), nil
+}
+
+type flatFieldsLevelItem struct {
+	structType *types.Struct
+	parent     *FlatField
+}
+
+func flattenStruct(typ types.Type, structType *types.Struct) FlatFields {
+	var result FlatFields
+
+	// seen contains named types already walked through on shallower
+	// depths: they cannot provide accessible fields anymore and it also
+	// prevents infinite recursion.
+	seen := map[*types.Named]struct{}{}
+	if named, ok := typ.(*types.Named); ok {
+		seen[named] = struct{}{}
+	}
+	found := map[string]struct{}{}
+
+	level := []flatFieldsLevelItem{{structType: structType}}
+	for len(level) > 0 {
+		var (
+			nextLevel  []flatFieldsLevelItem
+			levelNames = map[string]FlatFields{}
+			levelSeen  = map[*types.Named]struct{}{}
+		)
+		for _, item := range level {
+			for idx := 0; idx < item.structType.NumFields(); idx++ {
+				flatField := item.parent.child(item.structType.Field(idx), item.structType.Tag(idx), idx)
+				result = append(result, flatField)
+				levelNames[flatField.Name()] = append(levelNames[flatField.Name()], flatField)
+
+				if !flatField.Var.Embedded() {
+					continue
+				}
+				embeddedType := flatField.Var.Type()
+				if pointer, ok := embeddedType.(*types.Pointer); ok {
+					embeddedType = pointer.Elem()
+				}
+				if named, ok := embeddedType.(*types.Named); ok {
+					if _, ok := seen[named]; ok {
+						continue
+					}
+					levelSeen[named] = struct{}{}
+				}
+				embeddedStruct, ok := embeddedType.Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
+				nextLevel = append(nextLevel, flatFieldsLevelItem{
+					structType: embeddedStruct,
+					parent:     flatField,
+				})
+			}
+		}
+
+		for name, fields := range levelNames {
+			_, isFound := found[name]
+			status := FlatFieldStatusAccessible
+			switch {
+			case isFound:
+				status = FlatFieldStatusShadowed
+			case len(fields) > 1:
+				status = FlatFieldStatusAmbiguous
+			}
+			for _, field := range fields {
+				field.Status = status
+			}
+			found[name] = struct{}{}
+		}
+		for named := range levelSeen {
+			seen[named] = struct{}{}
+		}
+		level = nextLevel
+	}
+
+	return result
+}
+
+func (parent *FlatField) child(v *types.Var, tag string, idx int) *FlatField {
+	if parent == nil {
+		return &FlatField{
+			Var:      v,
+			Tag:      tag,
+			Path:     []*types.Var{v},
+			PathTags: []string{tag},
+			Index:    []int{idx},
+		}
+	}
+	return &FlatField{
+		Var:      v,
+		Tag:      tag,
+		Path:     append(append([]*types.Var{}, parent.Path...), v),
+		PathTags: append(append([]string{}, parent.PathTags...), tag),
+		Index:    append(append([]int{}, parent.Index...), idx),
+	}
+}
+
+// Accessible returns only the fields which could be selected by name.
+func (fields FlatFields) Accessible() FlatFields {
+	return fields.FilterByStatus(FlatFieldStatusAccessible)
+}
+
+// FilterByStatus returns only the fields with the specified status.
+func (fields FlatFields) FilterByStatus(status FlatFieldStatus) FlatFields {
+	var result FlatFields
+	for _, field := range fields {
+		if field.Status == status {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// FindByName returns the accessible field with the specified name
+// (or nil if there is no such field).
+func (fields FlatFields) FindByName(name string) *FlatField {
+	for _, field := range fields {
+		if field.Status == FlatFieldStatusAccessible && field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// FindByAccessPath returns the field with the specified access path
+// (like "Base.Meta.ID"), or nil if there is no such field.
+func (fields FlatFields) FindByAccessPath(accessPath string) *FlatField {
+	for _, field := range fields {
+		if field.AccessPath() == accessPath {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package gosrc_test
+
+import (
+	"go/build"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestStructFlatFields(t *testing.T) {
+	pkg := openTestPackage(t, "embedding")
+
+	fields, err := findStruct(t, pkg, "Entity").FlatFields()
+	require.NoError(t, err)
+
+	name := fields.FindByName("Name")
+	require.NotNil(t, name)
+	require.Equal(t, "Name", name.AccessPath())
+	require.NotNil(t, name.Field)
+	require.Equal(t, gosrc.FlatFieldStatusShadowed, fields.FindByAccessPath("Base.Meta.Name").Status)
+
+	id := fields.FindByName("ID")
+	require.NotNil(t, id)
+	require.Equal(t, "Base.Meta.ID", id.AccessPath())
+	require.Equal(t, []int{0, 0, 0}, id.Index)
+	require.True(t, id.IsPromoted())
+	require.True(t, id.IsInheritedBy("json"))
+
+	require.Nil(t, fields.FindByName("Version"))
+	require.Len(t, fields.FilterByStatus(gosrc.FlatFieldStatusAmbiguous), 2)
+
+	author := fields.FindByName("Author")
+	require.NotNil(t, author)
+	require.False(t, author.IsInheritedBy("json"))
+
+	fields, err = findStruct(t, pkg, "Node").FlatFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+}
+
+func TestMethodSet(t *testing.T) {
+	pkg := openTestPackage(t, "embedding")
+	entity := findStruct(t, pkg, "Entity")
+
+	methods, err := entity.MethodSet(false, nil)
+	require.NoError(t, err)
+	var names []string
+	for _, method := range methods {
+		names = append(names, method.Name())
+	}
+	require.ElementsMatch(t, []string{"GetID", "SetID", "GetVersion"}, names)
+	require.True(t, methods.FindByName("GetID").IsPromoted())
+	require.Nil(t, methods.FindByName("GetID").Func)
+	require.NotNil(t, methods.FindByName("GetVersion").Func)
+
+	methods, err = entity.MethodSet(true, nil)
+	require.NoError(t, err)
+	require.Len(t, methods, 4)
+	require.True(t, methods.FindByName("Touch").IsPointerReceiver())
+	require.Len(t, methods.Funcs(), 2)
+
+	fields, err := findStruct(t, pkg, "Holder").Fields()
+	require.NoError(t, err)
+	require.Len(t, fields[0].Methods(), 2)
+	require.NotNil(t, fields[1].MethodByName("Touch"))
+	stringerMethods, err := fields[2].MethodSet(nil)
+	require.NoError(t, err)
+	require.True(t, stringerMethods[0].IsInterfaceMethod())
+
+	// methods promoted from another package are resolved by the loader
+	loader := gosrc.NewLoader(&build.Default, nil)
+	methods, err = entity.MethodSet(true, loader)
+	require.NoError(t, err)
+	getID := methods.FindByName("GetID")
+	require.NotNil(t, getID.Func)
+	require.Equal(t, "Meta", getID.Func.ReceiverTypeName())
+	require.Len(t, methods.Funcs(), 4)
+	require.NotNil(t, methods.FindByName("Touch").Func)
+
+	entityFields, err := entity.Fields()
+	require.NoError(t, err)
+	baseMethods, err := entityFields[0].MethodSet(loader)
+	require.NoError(t, err)
+	require.NotNil(t, baseMethods.FindByName("SetID").Func)
+}
+
+func TestFieldResolveType(t *testing.T) {
+	pkg := openTestPackage(t, "embedding")
+	fields, err := findStruct(t, pkg, "Base").Fields()
+	require.NoError(t, err)
+
+	_, err = fields[0].ResolveType(nil)
+	require.Error(t, err)
+
+	loader := gosrc.NewLoader(&build.Default, nil)
+	meta, err := fields[0].ResolveType(loader)
+	require.NoError(t, err)
+	require.Equal(t, "Meta", meta.Name())
+	metaStruct := meta.ToStruct()
+	require.NotNil(t, metaStruct)
+	metaFields, err := metaStruct.Fields()
+	require.NoError(t, err)
+	require.Len(t, metaFields, 2)
+	require.Len(t, loader.Packages(), 1)
+
+	fields, err = findStruct(t, pkg, "Holder").Fields()
+	require.NoError(t, err)
+	entity, err := fields[0].ResolveType(nil)
+	require.NoError(t, err)
+	require.Equal(t, "Entity", entity.Name())
+}
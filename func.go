@@ -7,34 +7,119 @@ import (
 // Func represents one function of a source code file.
 type Func struct {
 	*ast.FuncDecl
+	File *File
 }
 
 // Funcs is a set of Func-s.
 type Funcs []*Func
 
-func newFunc(funcDecl *ast.FuncDecl) *Func {
+func newFunc(file *File, funcDecl *ast.FuncDecl) *Func {
 	return &Func{
 		FuncDecl: funcDecl,
+		File:     file,
 	}
 }
 
+// IsMethod returns true if the function has a receiver.
+func (fn Func) IsMethod() bool {
+	return fn.FuncDecl.Recv != nil && len(fn.FuncDecl.Recv.List) > 0
+}
+
+// ReceiverTypeName returns the name of the type of the receiver (without
+// pointer and type parameters), or an empty string if the function
+// is not a method.
+func (fn Func) ReceiverTypeName() string {
+	ident, _ := fn.receiverTypeIdent()
+	if ident == nil {
+		return ""
+	}
+	return ident.Name
+}
+
+// IsPointerReceiver returns true if the function is a method with
+// a pointer receiver.
+func (fn Func) IsPointerReceiver() bool {
+	if !fn.IsMethod() {
+		return false
+	}
+	_, ok := fn.FuncDecl.Recv.List[0].Type.(*ast.StarExpr)
+	return ok
+}
+
+// receiverTypeIdent returns the identifier of the receiver type and
+// the identifiers of the receiver type parameters (if any).
+//
+// For example, for "func (l *List[K, V]) Push()" it returns "List"
+// and ["K", "V"].
+func (fn Func) receiverTypeIdent() (*ast.Ident, []*ast.Ident) {
+	if !fn.IsMethod() {
+		return nil, nil
+	}
+	typ := fn.FuncDecl.Recv.List[0].Type
+	if parenExpr, ok := typ.(*ast.ParenExpr); ok {
+		typ = parenExpr.X
+	}
+	if starExpr, ok := typ.(*ast.StarExpr); ok {
+		typ = starExpr.X
+	}
+
+	var typeParamExprs []ast.Expr
+	switch casted := typ.(type) {
+	case *ast.IndexExpr:
+		typ = casted.X
+		typeParamExprs = []ast.Expr{casted.Index}
+	case *ast.IndexListExpr:
+		typ = casted.X
+		typeParamExprs = casted.Indices
+	}
+
+	ident, ok := typ.(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	var typeParams []*ast.Ident
+	for _, expr := range typeParamExprs {
+		typeParam, ok := expr.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		typeParams = append(typeParams, typeParam)
+	}
+	return ident, typeParams
+}
+
+// TypeParams returns the type parameters of the function. For methods
+// of generic types it returns the type parameters declared in the receiver
+// (for example "T" in "func (l *List[T]) Push(v T)").
+func (fn Func) TypeParams() TypeParams {
+	if !fn.IsMethod() {
+		return newTypeParams(fn.File, fn.FuncDecl.Type.TypeParams)
+	}
+
+	_, idents := fn.receiverTypeIdent()
+	var result TypeParams
+	for idx, ident := range idents {
+		result = append(result, &TypeParam{
+			Ident: ident,
+			Index: uint(idx),
+			Type:  fn.File.typeParamOf(ident),
+		})
+	}
+	return result
+}
+
+// IsGeneric returns true if the function has type parameters (or
+// if it is a method of a generic type).
+func (fn Func) IsGeneric() bool {
+	return len(fn.TypeParams()) > 0
+}
+
 // FindMethodsOf returns all methods of a specified type.
 func (funcs Funcs) FindMethodsOf(typName string) Funcs {
 	var result Funcs
 	for _, fn := range funcs {
-		for _, recv := range fn.FuncDecl.Recv.List {
-			cmpTyp := recv.Type
-			if starExpr, ok := cmpTyp.(*ast.StarExpr); ok {
-				cmpTyp = starExpr.X
-			}
-			ident, ok := cmpTyp.(*ast.Ident)
-			if !ok {
-				continue
-			}
-			if ident.Name == typName {
-				result = append(result, fn)
-				break
-			}
+		if fn.ReceiverTypeName() == typName {
+			result = append(result, fn)
 		}
 	}
 	return result
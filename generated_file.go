@@ -0,0 +1,293 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeneratedFile is a builder of a generated source code file of a package.
+// It is an io.Writer of the body of the file (everything after
+// the imports) and it manages the imports: every package referenced
+// by Import, QualifiedName or TypeString is imported automatically.
+//
+// Bytes (and WriteFile) prepend the header, the package clause and
+// the imports to the body and format the result with gofmt.
+type GeneratedFile struct {
+	// PkgPath is the path of the package of the file.
+	PkgPath string
+
+	// PkgName is the name of the package of the file.
+	PkgName string
+
+	// Generator is the name of the generator in the header
+	// "// Code generated by <Generator>. DO NOT EDIT.".
+	Generator string
+
+	imports       []*GeneratedImport
+	reservedNames map[string]struct{}
+	body          bytes.Buffer
+}
+
+// GeneratedImport is one import of a GeneratedFile.
+type GeneratedImport struct {
+	Path string
+
+	// Name is the name used to reference the package in the file.
+	Name string
+
+	// IsAliased is true if the import has an explicit name in
+	// the import declaration (because Name differs from the last element
+	// of Path).
+	IsAliased bool
+}
+
+// GeneratedImports is a set of GeneratedImport-s.
+type GeneratedImports []*GeneratedImport
+
+// NewGeneratedFile returns a new builder of a generated file of
+// the package with the specified path and name.
+//
+// If generator is empty, then the name of the executable is used.
+func NewGeneratedFile(pkgPath, pkgName, generator string) *GeneratedFile {
+	if generator == "" {
+		generator = filepath.Base(os.Args[0])
+	}
+	return &GeneratedFile{
+		PkgPath:       pkgPath,
+		PkgName:       pkgName,
+		Generator:     generator,
+		reservedNames: map[string]struct{}{},
+	}
+}
+
+// NewGeneratedFile returns a new builder of a generated file of
+// the package. Names declared in the package are reserved (see
+// GeneratedFile.ReserveNames), so they are not shadowed by imports.
+func (pkg *Package) NewGeneratedFile(generator string) *GeneratedFile {
+	file := NewGeneratedFile(pkg.Path(), pkg.Name, generator)
+	if pkg.Package != nil {
+		file.ReserveNames(pkg.Package.Scope().Names()...)
+	}
+	return file
+}
+
+// ReserveNames prevents the names from being used as names of imports
+// (for example, names of variables declared at the top level of
+// the generated file).
+func (file *GeneratedFile) ReserveNames(names ...string) {
+	for _, name := range names {
+		file.reservedNames[name] = struct{}{}
+	}
+}
+
+// Write implements io.Writer: it appends to the body of the file.
+func (file *GeneratedFile) Write(b []byte) (int, error) {
+	return file.body.Write(b)
+}
+
+// Printf appends the formatted text to the body of the file.
+func (file *GeneratedFile) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&file.body, format, args...)
+}
+
+// Imports returns the imports of the file sorted by path.
+func (file *GeneratedFile) Imports() GeneratedImports {
+	result := append(GeneratedImports{}, file.imports...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// FindByPath returns the import with the specified path (or nil if there
+// is no such import).
+func (imports GeneratedImports) FindByPath(path string) *GeneratedImport {
+	for _, _import := range imports {
+		if _import.Path == path {
+			return _import
+		}
+	}
+	return nil
+}
+
+// FindByName returns the import with the specified name (or nil if there
+// is no such import).
+func (imports GeneratedImports) FindByName(name string) *GeneratedImport {
+	for _, _import := range imports {
+		if _import.Name == name {
+			return _import
+		}
+	}
+	return nil
+}
+
+// Import adds the import of the package (if it is not added yet) and
+// returns the name to reference the package in the file. It returns
+// an empty string for the package of the file itself.
+//
+// The name is guessed by the path (like "yaml" for "gopkg.in/yaml.v3"),
+// see ImportAs to set the name explicitly.
+func (file *GeneratedFile) Import(pkgPath string) string {
+	return file.importPackage(pkgPath, guessPackageName(pkgPath))
+}
+
+// importPackage adds the import of the package with the preferred name
+// (a number is appended to the name if it is already used).
+func (file *GeneratedFile) importPackage(pkgPath, preferredName string) string {
+	if pkgPath == file.PkgPath {
+		return ""
+	}
+	if _import := GeneratedImports(file.imports).FindByPath(pkgPath); _import != nil {
+		return _import.Name
+	}
+	name := preferredName
+	for suffix := 2; !file.isNameAvailable(name); suffix++ {
+		name = preferredName + strconv.Itoa(suffix)
+	}
+	file.addImport(pkgPath, name)
+	return name
+}
+
+// ImportAs adds the import of the package with the specified name. It
+// returns an error if the package is already imported with another name
+// or if the name is already used.
+func (file *GeneratedFile) ImportAs(pkgPath, name string) error {
+	if pkgPath == file.PkgPath {
+		return fmt.Errorf("cannot import package '%s' into itself", pkgPath)
+	}
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid package name '%s'", name)
+	}
+	if _import := GeneratedImports(file.imports).FindByPath(pkgPath); _import != nil {
+		if _import.Name != name {
+			return fmt.Errorf("package '%s' is already imported as '%s'", pkgPath, _import.Name)
+		}
+		return nil
+	}
+	if !file.isNameAvailable(name) {
+		return fmt.Errorf("name '%s' is already used", name)
+	}
+	file.addImport(pkgPath, name)
+	return nil
+}
+
+func (file *GeneratedFile) addImport(pkgPath, name string) {
+	file.imports = append(file.imports, &GeneratedImport{
+		Path:      pkgPath,
+		Name:      name,
+		IsAliased: name != lastPathElement(pkgPath),
+	})
+}
+
+func (file *GeneratedFile) isNameAvailable(name string) bool {
+	if name == file.PkgName || token.IsKeyword(name) || types.Universe.Lookup(name) != nil {
+		return false
+	}
+	if _, ok := file.reservedNames[name]; ok {
+		return false
+	}
+	return GeneratedImports(file.imports).FindByName(name) == nil
+}
+
+// QualifiedName returns the name of the object of the package as it
+// should be referenced in the file, like "pkg.Name" (or "Name" for
+// the package of the file itself). The package is imported if required.
+func (file *GeneratedFile) QualifiedName(pkgPath, name string) string {
+	pkgName := file.Import(pkgPath)
+	if pkgName == "" {
+		return name
+	}
+	return pkgName + "." + name
+}
+
+// TypeString returns the type as it should be referenced in the file,
+// like "map[string]*pkg.Name". Packages of the referenced types are
+// imported if required.
+func (file *GeneratedFile) TypeString(typ types.Type) string {
+	return types.TypeString(typ, file.qualifier)
+}
+
+// qualifier implements types.Qualifier.
+func (file *GeneratedFile) qualifier(pkg *types.Package) string {
+	return file.importPackage(pkg.Path(), pkg.Name())
+}
+
+// Bytes returns the formatted content of the file.
+func (file *GeneratedFile) Bytes() ([]byte, error) {
+	var result bytes.Buffer
+	fmt.Fprintf(&result, "// Code generated by %s. DO NOT EDIT.\n\n", file.Generator)
+	fmt.Fprintf(&result, "package %s\n\n", file.PkgName)
+	if imports := file.Imports(); len(imports) > 0 {
+		result.WriteString("import (\n")
+		for _, _import := range imports {
+			if _import.IsAliased {
+				fmt.Fprintf(&result, "\t%s %s\n", _import.Name, strconv.Quote(_import.Path))
+			} else {
+				fmt.Fprintf(&result, "\t%s\n", strconv.Quote(_import.Path))
+			}
+		}
+		result.WriteString(")\n\n")
+	}
+	result.Write(file.body.Bytes())
+
+	formatted, err := format.Source(result.Bytes())
+	if err != nil {
+		return nil, ErrInvalidGeneratedCode{
+			Source: result.Bytes(),
+			Err:    err,
+		}
+	}
+	return formatted, nil
+}
+
+// WriteFile writes the formatted content of the file to the path.
+func (file *GeneratedFile) WriteFile(path string) error {
+	b, err := file.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("unable to write file '%s': %w", path, err)
+	}
+	return nil
+}
+
+var (
+	majorVersionRegexp   = regexp.MustCompile(`^v[0-9]+$`)
+	versionSuffixRegexp  = regexp.MustCompile(`\.v[0-9]+$`)
+	invalidNameRuneRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+func lastPathElement(pkgPath string) string {
+	return pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+}
+
+// guessPackageName returns the most likely name of the package by
+// its path (like "yaml" for "gopkg.in/yaml.v3", "gosrc" for
+// "github.com/xaionaro-go/gosrc/v2" and "errors" for
+// "github.com/go-errors/errors").
+func guessPackageName(pkgPath string) string {
+	parts := strings.Split(pkgPath, "/")
+	name := parts[len(parts)-1]
+	if majorVersionRegexp.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	name = versionSuffixRegexp.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	name = invalidNameRuneRegex.ReplaceAllString(name, "")
+	if name == "" || !token.IsIdentifier(name) {
+		name = "pkg" + name
+	}
+	return name
+}
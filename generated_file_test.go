@@ -0,0 +1,65 @@
+package gosrc_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestGeneratedFile(t *testing.T) {
+	file := gosrc.NewGeneratedFile("example.com/app/model", "model", "gen-test")
+	file.ReserveNames("yaml")
+
+	require.Equal(t, "", file.Import("example.com/app/model"))
+	require.Equal(t, "errors", file.Import("github.com/go-errors/errors"))
+	require.Equal(t, "errors2", file.Import("errors"))
+	require.Equal(t, "errors", file.Import("github.com/go-errors/errors"))
+	require.Equal(t, "yaml2", file.Import("gopkg.in/yaml.v3"))
+	require.NoError(t, file.ImportAs("github.com/pkg/foo/v2", "foo"))
+	require.Error(t, file.ImportAs("github.com/other/foo", "foo"))
+	require.Error(t, file.ImportAs("errors", "stderrors"))
+
+	other := types.NewPackage("example.com/app/storage", "store")
+	named := types.NewNamed(types.NewTypeName(0, other, "Item", nil), types.NewStruct(nil, nil), nil)
+	own := types.NewNamed(types.NewTypeName(0, types.NewPackage("example.com/app/model", "model"), "User", nil), types.NewStruct(nil, nil), nil)
+	require.Equal(t, "map[string]*store.Item", file.TypeString(types.NewMap(types.Typ[types.String], types.NewPointer(named))))
+	require.Equal(t, "[]User", file.TypeString(types.NewSlice(own)))
+	require.Equal(t, "time.Duration", file.QualifiedName("time", "Duration"))
+
+	file.Printf("var _ = %s\n", file.QualifiedName("errors", "New"))
+	file.Printf("var ( _ %s; _ = %s )\n", file.TypeString(named), file.QualifiedName("github.com/go-errors/errors", "New"))
+	file.Printf("var _ %s\nvar _ %s.Node\nvar _ %s.T\n",
+		file.QualifiedName("time", "Duration"), file.Import("gopkg.in/yaml.v3"), file.Import("github.com/pkg/foo/v2"))
+
+	b, err := file.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by gen-test. DO NOT EDIT.
+
+package model
+
+import (
+	errors2 "errors"
+	store "example.com/app/storage"
+	"github.com/go-errors/errors"
+	foo "github.com/pkg/foo/v2"
+	yaml2 "gopkg.in/yaml.v3"
+	"time"
+)
+
+var _ = errors2.New
+var (
+	_ store.Item
+	_ = errors.New
+)
+var _ time.Duration
+var _ yaml2.Node
+var _ foo.T
+`, string(b))
+
+	file.Printf("func {\n")
+	_, err = file.Bytes()
+	require.ErrorAs(t, err, &gosrc.ErrInvalidGeneratedCode{})
+}
@@ -0,0 +1,70 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestGenerics(t *testing.T) {
+	pkg := openTestPackage(t, "generics")
+
+	list := findStruct(t, pkg, "List")
+	require.True(t, list.IsGeneric())
+	require.Equal(t, []string{"T"}, list.TypeParams().Names())
+	require.NotNil(t, list.MethodByName("Push"))
+	require.Equal(t, []string{"T"}, list.MethodByName("Push").TypeParams().Names())
+
+	pair := findStruct(t, pkg, "Pair")
+	typeParams := pair.TypeParams()
+	require.Equal(t, []string{"K", "V"}, typeParams.Names())
+	require.True(t, typeParams[0].IsComparable())
+	require.Len(t, typeParams[1].Methods(), 1)
+	require.NotNil(t, pair.MethodByName("Get"))
+
+	fields, err := pair.Fields()
+	require.NoError(t, err)
+	require.True(t, fields[0].IsTypeParam())
+	require.Equal(t, []string{"K", "V"}, fields[1].TypeParams().Names())
+
+	sum := pkg.AllFuncs().FindByName("Sum")
+	require.Len(t, sum, 1)
+	require.False(t, sum[0].IsMethod())
+	require.Empty(t, pkg.Funcs().FindByName("Sum"))
+	sumTypeParams := sum[0].TypeParams()
+	require.Equal(t, []string{"N"}, sumTypeParams.Names())
+	terms, restricted := sumTypeParams[0].TypeSet()
+	require.True(t, restricted)
+	require.Len(t, terms, 3)
+}
+
+func TestInstantiations(t *testing.T) {
+	pkg := openTestPackage(t, "generics")
+
+	list := findStruct(t, pkg, "List")
+	listInstantiations := list.Instantiations(gosrc.Packages{pkg})
+	require.NotEmpty(t, listInstantiations)
+	concrete := listInstantiations.Concrete().Unique()
+	require.Len(t, concrete, 1)
+	require.Equal(t, "int", concrete[0].TypeArgs[0].String())
+
+	sum := pkg.AllFuncs().FindByName("Sum")[0]
+	sumInstantiations := sum.Instantiations(gosrc.Packages{pkg}).Concrete()
+	require.Len(t, sumInstantiations, 1)
+	require.True(t, sumInstantiations[0].IsFunc())
+	require.NotZero(t, sumInstantiations[0].Position().Line)
+	require.Equal(t, sumInstantiations[0].Position().Offset+len("Sum"), sumInstantiations[0].EndPosition().Offset)
+
+	var names []string
+	for _, instantiation := range pkg.Instantiations().Transitive() {
+		names = append(names, instantiation.String())
+	}
+	const pkgPath = "github.com/xaionaro-go/gosrc/testdata/generics"
+	require.ElementsMatch(t, []string{
+		pkgPath + ".List[int]",
+		pkgPath + ".element[int]",
+		pkgPath + ".Sum[float64]",
+		pkgPath + ".Pair[string, fmt.Stringer]",
+	}, names)
+}
@@ -0,0 +1,28 @@
+package gosrc_test
+
+import (
+	"go/build"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func openTestPackage(t *testing.T, name string) *gosrc.Package {
+	dir, err := gosrc.OpenDirectoryByPkgPath(&build.Default, "./testdata/"+name, false, false, false, nil)
+	require.NoError(t, err)
+	require.Len(t, dir.Packages, 1)
+	return dir.Packages[0]
+}
+
+func findStruct(t *testing.T, pkg *gosrc.Package, name string) *gosrc.Struct {
+	for _, file := range pkg.Files {
+		for _, s := range file.Structs() {
+			if s.Name() == name {
+				return s
+			}
+		}
+	}
+	t.Fatalf("struct '%s' not found", name)
+	return nil
+}
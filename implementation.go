@@ -0,0 +1,364 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// MissingMethod describes why a method required by an interface is not
+// provided by a type.
+type MissingMethod struct {
+	// Method is the method required by the interface.
+	Method *types.Func
+
+	// Found is the method of the type with the same name but with
+	// a different signature (or nil if there is no such method).
+	Found *types.Func
+
+	// OnlyPointerReceiver is true if the method exists, but only with
+	// a pointer receiver (thus it is missing only in the method set
+	// of the value type).
+	OnlyPointerReceiver bool
+}
+
+// String just implements fmt.Stringer
+func (missing MissingMethod) String() string {
+	switch {
+	case missing.OnlyPointerReceiver:
+		return fmt.Sprintf("method %s has a pointer receiver", missing.Method.Name())
+	case missing.Found != nil:
+		return fmt.Sprintf("method %s has wrong signature: have %s, want %s",
+			missing.Method.Name(), missing.Found.Type(), missing.Method.Type())
+	default:
+		return fmt.Sprintf("method %s is missing", missing.Method.Name())
+	}
+}
+
+// MissingMethods is a set of MissingMethod-s.
+type MissingMethods []MissingMethod
+
+// String just implements fmt.Stringer
+func (missings MissingMethods) String() string {
+	var result []string
+	for _, missing := range missings {
+		result = append(result, missing.String())
+	}
+	return strings.Join(result, "; ")
+}
+
+// Implementation describes if a type implements an interface.
+type Implementation struct {
+	// Type is the (non-pointer) type being checked.
+	Type types.Type
+
+	// Interface is the interface being checked.
+	Interface types.Type
+
+	// ByValue is true if the Type itself implements the interface.
+	ByValue bool
+
+	// ByPointer is true if the pointer to the Type implements the interface.
+	ByPointer bool
+
+	// Missing explains which methods are missing (for the pointer
+	// to the type, see also MissingMethod.OnlyPointerReceiver). It is empty
+	// if ByValue is true.
+	Missing MissingMethods
+}
+
+// Implementations is a set of Implementation-s.
+type Implementations []*Implementation
+
+// Implements returns true if either the type or the pointer to the type
+// implements the interface.
+func (impl Implementation) Implements() bool {
+	return impl.ByValue || impl.ByPointer
+}
+
+// OnlyByPointer returns true if the interface is implemented by the pointer
+// to the type, but not by the type itself.
+func (impl Implementation) OnlyByPointer() bool {
+	return impl.ByPointer && !impl.ByValue
+}
+
+// String just implements fmt.Stringer
+func (impl Implementation) String() string {
+	switch {
+	case impl.ByValue:
+		return fmt.Sprintf("%s implements %s", impl.Type, impl.Interface)
+	case impl.ByPointer:
+		return fmt.Sprintf("*%s implements %s", impl.Type, impl.Interface)
+	default:
+		return fmt.Sprintf("%s does not implement %s: %s", impl.Type, impl.Interface, impl.Missing)
+	}
+}
+
+// CheckImplementation checks if the type (or the pointer to it) implements
+// the interface, and explains which methods are missing if it does not.
+//
+// Methods are compared by names and signatures, where named types are
+// compared by their package paths and names. So the type and
+// the interface could be taken from packages type-checked independently
+// (like packages opened by a Loader).
+func CheckImplementation(typ types.Type, iface types.Type) (*Implementation, error) {
+	ifaceUnderlying, ok := iface.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", iface)
+	}
+	if pointer, ok := typ.(*types.Pointer); ok {
+		typ = pointer.Elem()
+	}
+
+	impl := &Implementation{
+		Type:      typ,
+		Interface: iface,
+	}
+	if types.IsInterface(typ) {
+		impl.Missing = missingMethods(typ, typ, ifaceUnderlying)
+		impl.ByValue = len(impl.Missing) == 0
+		return impl, nil
+	}
+
+	impl.Missing = missingMethods(typ, types.NewPointer(typ), ifaceUnderlying)
+	impl.ByValue = len(impl.Missing) == 0
+	impl.ByPointer = true
+	for _, missing := range impl.Missing {
+		if !missing.OnlyPointerReceiver {
+			impl.ByPointer = false
+		}
+	}
+	return impl, nil
+}
+
+func missingMethods(typ, pointer types.Type, iface *types.Interface) MissingMethods {
+	valueMethods := types.NewMethodSet(typ)
+	pointerMethods := types.NewMethodSet(pointer)
+
+	var result MissingMethods
+	for idx := 0; idx < iface.NumMethods(); idx++ {
+		want := iface.Method(idx)
+		if have := lookupMethod(valueMethods, want); have != nil {
+			if !identicalTypes(have.Type(), want.Type()) {
+				result = append(result, MissingMethod{Method: want, Found: have})
+			}
+			continue
+		}
+		if have := lookupMethod(pointerMethods, want); have != nil {
+			if !identicalTypes(have.Type(), want.Type()) {
+				result = append(result, MissingMethod{Method: want, Found: have})
+				continue
+			}
+			result = append(result, MissingMethod{Method: want, OnlyPointerReceiver: true})
+			continue
+		}
+		result = append(result, MissingMethod{Method: want})
+	}
+	return result
+}
+
+// lookupMethod returns the method of the method set with the same name
+// as the wanted method (unexported methods should also be declared in
+// a package with the same path).
+func lookupMethod(methodSet *types.MethodSet, want *types.Func) *types.Func {
+	for idx := 0; idx < methodSet.Len(); idx++ {
+		fn, ok := methodSet.At(idx).Obj().(*types.Func)
+		if !ok || fn.Name() != want.Name() {
+			continue
+		}
+		if !fn.Exported() && (fn.Pkg() == nil || want.Pkg() == nil || fn.Pkg().Path() != want.Pkg().Path()) {
+			continue
+		}
+		return fn
+	}
+	return nil
+}
+
+// identicalTypes is an analog of types.Identical, which also works for
+// types produced by independent type-checking passes: named types are
+// compared by their package paths and names (and names of parameters
+// of function types are ignored).
+func identicalTypes(a, b types.Type) bool {
+	if types.Identical(a, b) {
+		return true
+	}
+	return typeKey(a) == typeKey(b)
+}
+
+// typeKey returns the string representation of the type with packages
+// qualified by their paths and without names of parameters and results
+// of functions.
+func typeKey(typ types.Type) string {
+	signature, ok := typ.(*types.Signature)
+	if !ok {
+		return types.TypeString(typ, (*types.Package).Path)
+	}
+	tupleKey := func(tuple *types.Tuple, variadic bool) string {
+		var items []string
+		for idx := 0; idx < tuple.Len(); idx++ {
+			itemType := tuple.At(idx).Type()
+			if variadic && idx == tuple.Len()-1 {
+				items = append(items, "..."+typeKey(itemType.(*types.Slice).Elem()))
+				continue
+			}
+			items = append(items, typeKey(itemType))
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	}
+	return "func" + tupleKey(signature.Params(), signature.Variadic()) + tupleKey(signature.Results(), false)
+}
+
+// namedTypes returns all the package-level named types declared
+// in the packages (generic types are skipped, since they cannot be
+// checked without instantiation).
+func (pkgs Packages) namedTypes() []*types.Named {
+	var result []*types.Named
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Info == nil {
+			continue
+		}
+		for _, obj := range pkg.Info.Defs {
+			typeName, ok := obj.(*types.TypeName)
+			if !ok || typeName.IsAlias() || typeName.Parent() != typeName.Pkg().Scope() {
+				continue
+			}
+			named, ok := typeName.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			result = append(result, named)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Obj().Pkg().Path()+"."+result[i].Obj().Name() <
+			result[j].Obj().Pkg().Path()+"."+result[j].Obj().Name()
+	})
+	return result
+}
+
+// Implementors returns the types declared in the packages which implement
+// the interface (either by the value or by the pointer). Interfaces
+// embedding the interface are also returned.
+func (pkgs Packages) Implementors(iface types.Type) (Implementations, error) {
+	return pkgs.PartialImplementors(iface, 0)
+}
+
+// PartialImplementors is the same as Implementors, but also returns the
+// types which lack up to maxMissing methods of the interface (see
+// Implementation.Missing to find out which ones).
+func (pkgs Packages) PartialImplementors(iface types.Type, maxMissing int) (Implementations, error) {
+	if !types.IsInterface(iface) {
+		return nil, fmt.Errorf("%s is not an interface", iface)
+	}
+
+	var result Implementations
+	for _, named := range pkgs.namedTypes() {
+		if identicalTypes(named, iface) {
+			continue
+		}
+		impl, err := CheckImplementation(named, iface)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check if %s implements %s: %w", named, iface, err)
+		}
+		if !impl.Implements() && len(impl.Missing) > maxMissing {
+			continue
+		}
+		result = append(result, impl)
+	}
+	return result, nil
+}
+
+// ImplementedBy returns the interfaces declared in the packages which
+// are implemented by the type (or by the pointer to the type, see
+// Implementation.OnlyByPointer).
+func (pkgs Packages) ImplementedBy(typ types.Type) (Implementations, error) {
+	var result Implementations
+	for _, named := range pkgs.namedTypes() {
+		if !types.IsInterface(named) || identicalTypes(named, typ) {
+			continue
+		}
+		impl, err := CheckImplementation(typ, named)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check if %s implements %s: %w", typ, named, err)
+		}
+		if !impl.Implements() {
+			continue
+		}
+		result = append(result, impl)
+	}
+	return result, nil
+}
+
+// LookupType finds a package-level type by its package path and name
+// in the packages and in all the packages imported by them (recursively).
+// For example: pkgs.LookupType("io", "Writer").
+func (pkgs Packages) LookupType(pkgPath, name string) (types.Type, error) {
+	visited := map[*types.Package]struct{}{}
+	var lookup func(typesPkg *types.Package) types.Type
+	lookup = func(typesPkg *types.Package) types.Type {
+		if typesPkg == nil {
+			return nil
+		}
+		if _, ok := visited[typesPkg]; ok {
+			return nil
+		}
+		visited[typesPkg] = struct{}{}
+		if typesPkg.Path() == pkgPath {
+			if typeName, ok := typesPkg.Scope().Lookup(name).(*types.TypeName); ok {
+				return typeName.Type()
+			}
+			return nil
+		}
+		for _, imported := range typesPkg.Imports() {
+			if typ := lookup(imported); typ != nil {
+				return typ
+			}
+		}
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		// Declared types are looked up through Info first, since they
+		// are identical to the types of the fields of the structures.
+		if pkg.Info != nil {
+			for _, obj := range pkg.Info.Defs {
+				typeName, ok := obj.(*types.TypeName)
+				if !ok || typeName.Pkg() == nil || typeName.Pkg().Path() != pkgPath || typeName.Name() != name {
+					continue
+				}
+				if typeName.Parent() != typeName.Pkg().Scope() {
+					continue
+				}
+				return typeName.Type(), nil
+			}
+		}
+		if typ := lookup(pkg.Package); typ != nil {
+			return typ, nil
+		}
+	}
+	return nil, fmt.Errorf("type '%s.%s' is not found", pkgPath, name)
+}
+
+// Implementors returns the types declared in the packages which implement
+// this interface type.
+func (astTypeSpec AstTypeSpec) Implementors(pkgs Packages) (Implementations, error) {
+	typ := astTypeSpec.Type()
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for type '%s'", astTypeSpec.Name())
+	}
+	return pkgs.Implementors(typ)
+}
+
+// ImplementedBy returns the interfaces declared in the packages which are
+// implemented by this type.
+func (astTypeSpec AstTypeSpec) ImplementedBy(pkgs Packages) (Implementations, error) {
+	typ := astTypeSpec.Type()
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for type '%s'", astTypeSpec.Name())
+	}
+	return pkgs.ImplementedBy(typ)
+}
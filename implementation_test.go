@@ -0,0 +1,87 @@
+package gosrc_test
+
+import (
+	"go/build"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestImplementors(t *testing.T) {
+	pkg := openTestPackage(t, "implementation")
+	pkgs := gosrc.Packages{pkg}
+
+	const pkgPath = "github.com/xaionaro-go/gosrc/testdata/implementation"
+	plugin, err := pkgs.LookupType(pkgPath, "Plugin")
+	require.NoError(t, err)
+
+	impls, err := pkgs.Implementors(plugin)
+	require.NoError(t, err)
+	require.Len(t, impls, 2)
+	require.Equal(t, pkgPath+".PointerPlugin", impls[0].Type.String())
+	require.True(t, impls[0].OnlyByPointer())
+	require.Equal(t, pkgPath+".ValuePlugin", impls[1].Type.String())
+	require.True(t, impls[1].ByValue)
+
+	impls, err = pkgs.PartialImplementors(plugin, 2)
+	require.NoError(t, err)
+	require.Len(t, impls, 4)
+	require.Equal(t, pkgPath+".AlmostPlugin", impls[0].Type.String())
+	require.Len(t, impls[0].Missing, 2)
+	require.NotNil(t, impls[0].Missing[0].Found)
+	require.Nil(t, impls[0].Missing[1].Found)
+	require.Contains(t, impls[0].String(), "method Start is missing")
+
+	writer, err := pkgs.LookupType("io", "Writer")
+	require.NoError(t, err)
+	impls, err = pkgs.Implementors(writer)
+	require.NoError(t, err)
+	require.Len(t, impls, 1)
+	require.True(t, impls[0].OnlyByPointer())
+
+	pointerPlugin, err := pkgs.LookupType(pkgPath, "PointerPlugin")
+	require.NoError(t, err)
+	impl, err := gosrc.CheckImplementation(pointerPlugin, plugin)
+	require.NoError(t, err)
+	require.Len(t, impl.Missing, 2)
+	require.True(t, impl.Missing[0].OnlyPointerReceiver)
+
+	impls, err = pkgs.ImplementedBy(pointerPlugin)
+	require.NoError(t, err)
+	require.Len(t, impls, 1)
+	require.Equal(t, plugin, impls[0].Interface)
+}
+
+func TestImplementorsOfOtherPackage(t *testing.T) {
+	const pkgPath = "github.com/xaionaro-go/gosrc/testdata/implementation"
+	loader := gosrc.NewLoader(&build.Default, nil)
+	pluginPkg, err := loader.Load(pkgPath + "/plugin")
+	require.NoError(t, err)
+	runnerPkg, err := loader.Load(pkgPath + "/runner")
+	require.NoError(t, err)
+	pkgs := gosrc.Packages{pluginPkg, runnerPkg}
+
+	plugin, err := pkgs.LookupType(pkgPath+"/plugin", "Plugin")
+	require.NoError(t, err)
+	impls, err := pkgs.Implementors(plugin)
+	require.NoError(t, err)
+	require.Len(t, impls, 1)
+	require.Equal(t, pkgPath+"/runner.Runner", impls[0].Type.String())
+	require.True(t, impls[0].ByValue)
+
+	broken, err := pkgs.LookupType(pkgPath+"/runner", "Broken")
+	require.NoError(t, err)
+	impl, err := gosrc.CheckImplementation(broken, plugin)
+	require.NoError(t, err)
+	require.False(t, impl.Implements())
+	require.Len(t, impl.Missing, 1)
+	require.NotNil(t, impl.Missing[0].Found)
+
+	runner, err := pkgs.LookupType(pkgPath+"/runner", "Runner")
+	require.NoError(t, err)
+	impls, err = pkgs.ImplementedBy(runner)
+	require.NoError(t, err)
+	require.Len(t, impls, 1)
+	require.Equal(t, plugin, impls[0].Interface)
+}
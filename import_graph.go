@@ -0,0 +1,390 @@
+package gosrc
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ImportEdge is one import of a package by a file of another package.
+type ImportEdge struct {
+	// From is the path of the importing package.
+	From string
+
+	// To is the path of the imported package.
+	To string
+
+	Package    *Package
+	File       *File
+	ImportSpec *ast.ImportSpec
+	Position   token.Position
+}
+
+// ImportEdges is a set of ImportEdge-s.
+type ImportEdges []*ImportEdge
+
+// String just implements fmt.Stringer
+func (edge ImportEdge) String() string {
+	return fmt.Sprintf("%s -> %s (at %s)", edge.From, edge.To, edge.Position)
+}
+
+// ImportGraph is a graph of imports between packages. Nodes are package
+// paths: both of the packages the graph was built of, and of the packages
+// imported by them (the latter are leafs, unless they are loaded as well).
+//
+// External test packages ("pkg_test") are represented with the "_test"
+// suffix in the path.
+type ImportGraph struct {
+	packages   map[string]*Package
+	imports    map[string]ImportEdges
+	importedBy map[string]ImportEdges
+}
+
+// NewImportGraph builds the graph of imports of the packages. The packages
+// could be opened with "onlyFiles".
+func NewImportGraph(pkgs Packages) (*ImportGraph, error) {
+	graph := &ImportGraph{
+		packages:   map[string]*Package{},
+		imports:    map[string]ImportEdges{},
+		importedBy: map[string]ImportEdges{},
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		from := pkg.qualifiedPath()
+		graph.packages[from] = pkg
+		if _, ok := graph.imports[from]; !ok {
+			graph.imports[from] = nil
+		}
+		for _, file := range pkg.Files {
+			if file.Package != pkg {
+				continue
+			}
+			for _, importSpec := range file.Ast.Imports {
+				to, err := strconv.Unquote(importSpec.Path.Value)
+				if err != nil {
+					return nil, fmt.Errorf("unable to Unquote import path <%s>: %w", importSpec.Path.Value, err)
+				}
+				if to == "C" {
+					// cgo is not a package
+					continue
+				}
+				edge := &ImportEdge{
+					From:       from,
+					To:         to,
+					Package:    pkg,
+					File:       file,
+					ImportSpec: importSpec,
+					Position:   pkg.position(importSpec.Path.Pos()),
+				}
+				graph.imports[from] = append(graph.imports[from], edge)
+				graph.importedBy[to] = append(graph.importedBy[to], edge)
+				if _, ok := graph.imports[to]; !ok {
+					graph.imports[to] = nil
+				}
+			}
+		}
+	}
+	return graph, nil
+}
+
+// ImportGraph returns the graph of imports of the packages opened so far.
+func (loader *Loader) ImportGraph() (*ImportGraph, error) {
+	return NewImportGraph(loader.Packages())
+}
+
+// PackagePaths returns the paths of all the packages of the graph
+// (in the alphabetical order).
+func (graph *ImportGraph) PackagePaths() []string {
+	result := make([]string, 0, len(graph.imports))
+	for pkgPath := range graph.imports {
+		result = append(result, pkgPath)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Package returns the package with the specified path (or nil if
+// the package was only imported, but not loaded).
+func (graph *ImportGraph) Package(pkgPath string) *Package {
+	return graph.packages[pkgPath]
+}
+
+// ImportEdges returns all the imports made by the package (one edge
+// per import declaration, so a package imported in multiple files is
+// listed multiple times).
+func (graph *ImportGraph) ImportEdges(pkgPath string) ImportEdges {
+	return graph.imports[pkgPath]
+}
+
+// ImportedByEdges returns all the imports of the package made by other
+// packages (one edge per import declaration).
+func (graph *ImportGraph) ImportedByEdges(pkgPath string) ImportEdges {
+	return graph.importedBy[pkgPath]
+}
+
+// Imports returns the paths of the packages directly imported by
+// the package (in the alphabetical order).
+func (graph *ImportGraph) Imports(pkgPath string) []string {
+	return uniquePaths(graph.imports[pkgPath], func(edge *ImportEdge) string { return edge.To })
+}
+
+// ImportedBy returns the paths of the packages directly importing
+// the package (in the alphabetical order).
+func (graph *ImportGraph) ImportedBy(pkgPath string) []string {
+	return uniquePaths(graph.importedBy[pkgPath], func(edge *ImportEdge) string { return edge.From })
+}
+
+func uniquePaths(edges ImportEdges, pathOf func(*ImportEdge) string) []string {
+	m := map[string]struct{}{}
+	var result []string
+	for _, edge := range edges {
+		pkgPath := pathOf(edge)
+		if _, ok := m[pkgPath]; ok {
+			continue
+		}
+		m[pkgPath] = struct{}{}
+		result = append(result, pkgPath)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Path returns the shortest chain of imports from one package to another,
+// including both of them (this answers "why does A import B?"). It returns
+// nil if the latter is not imported by the former (even transitively).
+func (graph *ImportGraph) Path(from, to string) []string {
+	if _, ok := graph.imports[from]; !ok {
+		return nil
+	}
+	if from == to {
+		return []string{from}
+	}
+	via := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		for _, next := range graph.Imports(pkgPath) {
+			if _, ok := via[next]; ok {
+				continue
+			}
+			via[next] = pkgPath
+			if next == to {
+				result := []string{to}
+				for pkgPath := via[to]; pkgPath != ""; pkgPath = via[pkgPath] {
+					result = append([]string{pkgPath}, result...)
+				}
+				return result
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// Cycles returns the groups of packages which (transitively) import each
+// other (strongly connected components of the graph). Packages inside
+// a group are sorted alphabetically, and the groups are sorted by their
+// first packages.
+func (graph *ImportGraph) Cycles() [][]string {
+	var (
+		index   = map[string]int{}
+		lowLink = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		result  [][]string
+	)
+	var strongConnect func(pkgPath string)
+	strongConnect = func(pkgPath string) {
+		index[pkgPath] = len(index)
+		lowLink[pkgPath] = index[pkgPath]
+		stack = append(stack, pkgPath)
+		onStack[pkgPath] = true
+
+		selfImport := false
+		for _, next := range graph.Imports(pkgPath) {
+			if next == pkgPath {
+				selfImport = true
+			}
+			if _, ok := index[next]; !ok {
+				strongConnect(next)
+				if lowLink[next] < lowLink[pkgPath] {
+					lowLink[pkgPath] = lowLink[next]
+				}
+			} else if onStack[next] && index[next] < lowLink[pkgPath] {
+				lowLink[pkgPath] = index[next]
+			}
+		}
+
+		if lowLink[pkgPath] != index[pkgPath] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == pkgPath {
+				break
+			}
+		}
+		if len(component) > 1 || selfImport {
+			sort.Strings(component)
+			result = append(result, component)
+		}
+	}
+	for _, pkgPath := range graph.PackagePaths() {
+		if _, ok := index[pkgPath]; !ok {
+			strongConnect(pkgPath)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// cycleOf returns a closed chain of imports through the packages of
+// the group (the first package is repeated in the end).
+func (graph *ImportGraph) cycleOf(component []string) []string {
+	start := component[0]
+	var best []string
+	for _, next := range graph.Imports(start) {
+		if next == start {
+			return []string{start, start}
+		}
+		path := graph.Path(next, start)
+		if path != nil && (best == nil || len(path) < len(best)) {
+			best = path
+		}
+	}
+	return append([]string{start}, best...)
+}
+
+// TopologicalOrder returns the paths of all the packages of the graph
+// ordered so, that each package goes after all the packages it imports
+// (ties are resolved alphabetically). It returns ErrImportCycle if
+// there is a cycle of imports.
+func (graph *ImportGraph) TopologicalOrder() ([]string, error) {
+	if cycles := graph.Cycles(); len(cycles) > 0 {
+		return nil, ErrImportCycle{Cycle: graph.cycleOf(cycles[0])}
+	}
+
+	pending := map[string]int{}
+	var ready []string
+	for _, pkgPath := range graph.PackagePaths() {
+		pending[pkgPath] = len(graph.Imports(pkgPath))
+		if pending[pkgPath] == 0 {
+			ready = append(ready, pkgPath)
+		}
+	}
+	result := make([]string, 0, len(pending))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		pkgPath := ready[0]
+		ready = ready[1:]
+		result = append(result, pkgPath)
+		for _, importer := range graph.ImportedBy(pkgPath) {
+			pending[importer]--
+			if pending[importer] == 0 {
+				ready = append(ready, importer)
+			}
+		}
+	}
+	return result, nil
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format. Packages which
+// were only imported (but not loaded) are drawn dashed.
+func (graph *ImportGraph) WriteDOT(w io.Writer) error {
+	pkgPaths := graph.PackagePaths()
+	ids := map[string]int{}
+	if _, err := fmt.Fprintln(w, "digraph imports {"); err != nil {
+		return err
+	}
+	for idx, pkgPath := range pkgPaths {
+		ids[pkgPath] = idx
+		style := ""
+		if graph.packages[pkgPath] == nil {
+			style = ", style=dashed"
+		}
+		if _, err := fmt.Fprintf(w, "\tn%d [label=%s%s];\n", idx, strconv.Quote(pkgPath), style); err != nil {
+			return err
+		}
+	}
+	for _, pkgPath := range pkgPaths {
+		for _, imported := range graph.Imports(pkgPath) {
+			if _, err := fmt.Fprintf(w, "\tn%d -> n%d;\n", ids[pkgPath], ids[imported]); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (graph *ImportGraph) WriteMermaid(w io.Writer) error {
+	pkgPaths := graph.PackagePaths()
+	ids := map[string]int{}
+	if _, err := fmt.Fprintln(w, "graph LR"); err != nil {
+		return err
+	}
+	for idx, pkgPath := range pkgPaths {
+		ids[pkgPath] = idx
+		if _, err := fmt.Fprintf(w, "\tn%d[\"%s\"]\n", idx, pkgPath); err != nil {
+			return err
+		}
+	}
+	for _, pkgPath := range pkgPaths {
+		for _, imported := range graph.Imports(pkgPath) {
+			if _, err := fmt.Fprintf(w, "\tn%d --> n%d\n", ids[pkgPath], ids[imported]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importGraphJSON is the structure of the output of ImportGraph.WriteJSON.
+type importGraphJSON struct {
+	Packages []importGraphPackageJSON `json:"packages"`
+}
+
+type importGraphPackageJSON struct {
+	Path    string   `json:"path"`
+	Name    string   `json:"name,omitempty"`
+	Loaded  bool     `json:"loaded"`
+	Imports []string `json:"imports"`
+}
+
+// WriteJSON writes the graph in JSON: an object with field "packages",
+// which is a list of objects with fields "path", "name", "loaded"
+// and "imports".
+func (graph *ImportGraph) WriteJSON(w io.Writer) error {
+	var out importGraphJSON
+	for _, pkgPath := range graph.PackagePaths() {
+		item := importGraphPackageJSON{
+			Path:    pkgPath,
+			Imports: graph.Imports(pkgPath),
+		}
+		if item.Imports == nil {
+			item.Imports = []string{}
+		}
+		if pkg := graph.packages[pkgPath]; pkg != nil {
+			item.Name = pkg.Name
+			item.Loaded = true
+		}
+		out.Packages = append(out.Packages, item)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(out)
+}
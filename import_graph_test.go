@@ -0,0 +1,116 @@
+package gosrc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/build"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestImportGraph(t *testing.T) {
+	const base = "github.com/xaionaro-go/gosrc/testdata/imports/"
+	loader := gosrc.NewLoader(&build.Default, nil)
+	for _, name := range []string{"app", "service", "store"} {
+		_, err := loader.Load(base + name)
+		require.NoError(t, err)
+	}
+	graph, err := loader.ImportGraph()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"fmt", base + "service"}, graph.Imports(base+"app"))
+	require.Equal(t, []string{base + "service"}, graph.ImportedBy(base+"store"))
+	require.Nil(t, graph.Package("strings"))
+	require.NotNil(t, graph.Package(base+"store"))
+
+	edges := graph.ImportEdges(base + "service")
+	require.Len(t, edges, 1)
+	require.Equal(t, 4, edges[0].Position.Line)
+
+	require.Equal(t, []string{base + "app", base + "service", base + "store", "strings"}, graph.Path(base+"app", "strings"))
+	require.Nil(t, graph.Path(base+"store", base+"app"))
+
+	require.Empty(t, graph.Cycles())
+	order, err := graph.TopologicalOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"fmt", "strings", base + "store", base + "service", base + "app"}, order)
+
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteMermaid(&buf))
+	require.Contains(t, buf.String(), "graph LR\n")
+	require.Contains(t, buf.String(), " --> ")
+
+	buf.Reset()
+	require.NoError(t, graph.WriteDOT(&buf))
+	require.Contains(t, buf.String(), `[label="strings", style=dashed];`)
+
+	buf.Reset()
+	require.NoError(t, graph.WriteJSON(&buf))
+	var out struct {
+		Packages []struct {
+			Path    string
+			Loaded  bool
+			Imports []string
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Packages, 5)
+	require.Equal(t, "fmt", out.Packages[0].Path)
+	require.False(t, out.Packages[0].Loaded)
+	require.Equal(t, base+"app", out.Packages[1].Path)
+	require.True(t, out.Packages[1].Loaded)
+}
+
+func TestImportGraphCycle(t *testing.T) {
+	var pkgs gosrc.Packages
+	for _, name := range []string{"a", "b"} {
+		dir, err := gosrc.OpenDirectoryByPkgPath(&build.Default, "./testdata/importcycle/"+name, false, false, true, nil)
+		require.NoError(t, err)
+		pkgs = append(pkgs, dir.Packages...)
+	}
+	graph, err := gosrc.NewImportGraph(pkgs)
+	require.NoError(t, err)
+
+	const base = "github.com/xaionaro-go/gosrc/testdata/importcycle/"
+	require.Equal(t, [][]string{{base + "a", base + "b"}}, graph.Cycles())
+
+	_, err = graph.TopologicalOrder()
+	var errCycle gosrc.ErrImportCycle
+	require.ErrorAs(t, err, &errCycle)
+	require.Equal(t, []string{base + "a", base + "b", base + "a"}, errCycle.Cycle)
+}
+
+func TestLayerRules(t *testing.T) {
+	const base = "github.com/xaionaro-go/gosrc/testdata/imports/"
+	loader := gosrc.NewLoader(&build.Default, nil)
+	for _, name := range []string{"app", "service", "store"} {
+		_, err := loader.Load(base + name)
+		require.NoError(t, err)
+	}
+	graph, err := loader.ImportGraph()
+	require.NoError(t, err)
+
+	rules, err := gosrc.LoadLayerRules("testdata/imports/layers.conf")
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+	require.Equal(t, gosrc.LayerRuleKindDeny, rules[0].Kind)
+	require.Equal(t, []string{"imports/service", "imports/app"}, rules[0].To)
+	require.Equal(t, gosrc.LayerRuleKindOnly, rules[3].Kind)
+	require.Equal(t, 6, rules[3].Line)
+
+	violations := rules.Check(graph)
+	require.Len(t, violations, 2)
+	require.Equal(t, rules[1], violations[0].Rule)
+	require.Equal(t, base+"service", violations[0].Edge.From)
+	require.Equal(t, 4, violations[0].Edge.Position.Line)
+	require.Equal(t, rules[3], violations[1].Rule)
+	require.Equal(t, "strings", violations[1].Edge.To)
+
+	_, err = gosrc.ParseLayerRules(strings.NewReader("a may import b"), "rules")
+	require.ErrorContains(t, err, "rules:1:")
+	_, err = gosrc.ParseLayerRules(strings.NewReader("only , may import b"), "rules")
+	require.Error(t, err)
+}
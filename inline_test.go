@@ -0,0 +1,50 @@
+package gosrc_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldInlineTypes(t *testing.T) {
+	pkg := openTestPackage(t, "inline")
+	fields, err := findStruct(t, pkg, "Config").Fields()
+	require.NoError(t, err)
+
+	server := fields[0].InlineStruct()
+	require.NotNil(t, server)
+	require.True(t, server.IsAnonymous())
+	require.Equal(t, "struct:Config.Server", server.String())
+	require.Equal(t, fields[0], server.ParentField)
+	serverFields, err := server.Fields()
+	require.NoError(t, err)
+	require.Len(t, serverFields, 2)
+	addrTag, ok := serverFields[0].TagGet("yaml")
+	require.True(t, ok)
+	require.Equal(t, "addr", addrTag)
+	require.Equal(t, "TLS is the TLS configuration.\n", serverFields[1].Doc.Text())
+
+	tls := serverFields[1].InlineStruct()
+	require.NotNil(t, tls)
+	require.Equal(t, "struct:Config.Server.TLS", tls.String())
+	tlsFields, err := tls.Fields()
+	require.NoError(t, err)
+	require.Equal(t, "string", tlsFields[0].TypeValue.Type.String())
+
+	flatFields, err := server.FlatFields()
+	require.NoError(t, err)
+	require.Len(t, flatFields, 2)
+
+	routes := fields[1].InlineStruct()
+	require.NotNil(t, routes)
+	require.Nil(t, fields[1].InlineInterface())
+
+	logger := fields[2].InlineInterface()
+	require.NotNil(t, logger)
+	methods := logger.ExplicitMethods()
+	require.Len(t, methods, 1)
+	require.Equal(t, "Printf", methods[0].Name())
+	require.NotNil(t, methods[0].Object())
+	require.True(t, methods[0].Object().Type().(*types.Signature).Variadic())
+}
@@ -0,0 +1,240 @@
+package gosrc
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Instantiation represents one instantiation of a generic type or function
+// (for example "Box[int]" or "Sum[float64]").
+type Instantiation struct {
+	// Ident is the identifier of the generic type or function at the place
+	// where it is instantiated. It is nil for instantiations found
+	// by Instantiations.Transitive.
+	Ident *ast.Ident
+
+	// File is the file where the instantiation takes place (or nil,
+	// see Ident).
+	File *File
+
+	// Origin is the generic type (*types.TypeName) or
+	// function (*types.Func) being instantiated.
+	Origin types.Object
+
+	// TypeArgs are the type arguments of the instantiation.
+	TypeArgs []types.Type
+
+	// Type is the instantiated type: *types.Named for types and
+	// *types.Signature for functions.
+	Type types.Type
+}
+
+// Instantiations is a set of Instantiation-s.
+type Instantiations []*Instantiation
+
+// String just implements fmt.Stringer
+func (instantiation Instantiation) String() string {
+	var typeArgs []string
+	for _, typeArg := range instantiation.TypeArgs {
+		typeArgs = append(typeArgs, typeArg.String())
+	}
+	return instantiation.OriginPath() + "." + instantiation.Origin.Name() + "[" + strings.Join(typeArgs, ", ") + "]"
+}
+
+// OriginPath returns the package path of the generic type or function.
+func (instantiation Instantiation) OriginPath() string {
+	if instantiation.Origin.Pkg() == nil {
+		return ""
+	}
+	return instantiation.Origin.Pkg().Path()
+}
+
+// IsFunc returns true if this is an instantiation of a generic function.
+func (instantiation Instantiation) IsFunc() bool {
+	_, ok := instantiation.Origin.(*types.Func)
+	return ok
+}
+
+// IsConcrete returns true if the type arguments do not reference
+// any type parameters (for example "Box[int]", but not "Box[T]" inside
+// of a generic function).
+func (instantiation Instantiation) IsConcrete() bool {
+	for _, typeArg := range instantiation.TypeArgs {
+		if len(typeParamsOf(typeArg)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Instantiations returns all the instantiations of generic types and
+// functions found in the package (including instantiations of types
+// and functions defined in other packages).
+//
+// The package should be opened without "onlyFiles".
+func (pkg *Package) Instantiations() Instantiations {
+	if pkg == nil || pkg.Info == nil {
+		return nil
+	}
+
+	var result Instantiations
+	for ident, instance := range pkg.Info.Instances {
+		origin := instanceOrigin(pkg.Info, ident, instance)
+		if origin == nil {
+			continue
+		}
+		var typeArgs []types.Type
+		for idx := 0; idx < instance.TypeArgs.Len(); idx++ {
+			typeArgs = append(typeArgs, instance.TypeArgs.At(idx))
+		}
+		result = append(result, &Instantiation{
+			Ident:    ident,
+			File:     pkg.Files.findByPos(ident.Pos()),
+			Origin:   origin,
+			TypeArgs: typeArgs,
+			Type:     instance.Type,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Ident.Pos() < result[j].Ident.Pos()
+	})
+	return result
+}
+
+func instanceOrigin(info *types.Info, ident *ast.Ident, instance types.Instance) types.Object {
+	if named, ok := instance.Type.(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	obj := info.Uses[ident]
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.Origin()
+	}
+	return obj
+}
+
+// Instantiations returns all the instantiations of generic types and
+// functions found in all the packages.
+func (pkgs Packages) Instantiations() Instantiations {
+	var result Instantiations
+	for _, pkg := range pkgs {
+		result = append(result, pkg.Instantiations()...)
+	}
+	return result
+}
+
+// Instantiations returns all the instantiations of generic types and
+// functions found in the directory.
+func (dir *Directory) Instantiations() Instantiations {
+	return dir.Packages.Instantiations()
+}
+
+// Instantiations returns all the instantiations of the generic type found
+// in the specified packages.
+func (astTypeSpec AstTypeSpec) Instantiations(pkgs Packages) Instantiations {
+	return pkgs.Instantiations().FilterByOrigin(astTypeSpec.File.Package.Path(), astTypeSpec.Name())
+}
+
+// Instantiations returns all the instantiations of the generic function
+// found in the specified packages.
+func (fn Func) Instantiations(pkgs Packages) Instantiations {
+	if fn.IsMethod() {
+		return nil
+	}
+	return pkgs.Instantiations().FilterByOrigin(fn.File.Package.Path(), fn.FuncDecl.Name.Name)
+}
+
+// FilterByOrigin returns only instantiations of the generic type or function
+// with the specified package path and name.
+func (instantiations Instantiations) FilterByOrigin(pkgPath, name string) Instantiations {
+	var result Instantiations
+	for _, instantiation := range instantiations {
+		if instantiation.OriginPath() != pkgPath || instantiation.Origin.Name() != name {
+			continue
+		}
+		result = append(result, instantiation)
+	}
+	return result
+}
+
+// Concrete returns only the instantiations which type arguments do not
+// reference type parameters.
+func (instantiations Instantiations) Concrete() Instantiations {
+	var result Instantiations
+	for _, instantiation := range instantiations {
+		if !instantiation.IsConcrete() {
+			continue
+		}
+		result = append(result, instantiation)
+	}
+	return result
+}
+
+// Unique returns the instantiations without duplicates: only the first
+// instantiation is left for each combination of origin and type arguments.
+func (instantiations Instantiations) Unique() Instantiations {
+	var result Instantiations
+	for _, instantiation := range instantiations {
+		if result.find(instantiation) != nil {
+			continue
+		}
+		result = append(result, instantiation)
+	}
+	return result
+}
+
+// Transitive returns the concrete instantiations with addition of
+// instantiations of generic types they depend on. For example, if
+// "List[T]" has a field of type "*element[T]", then for "List[int]"
+// it also returns "element[int]".
+func (instantiations Instantiations) Transitive() Instantiations {
+	result := instantiations.Concrete().Unique()
+	for idx := 0; idx < len(result); idx++ {
+		named, ok := result[idx].Type.(*types.Named)
+		if !ok {
+			continue
+		}
+		walkType(named.Underlying(), func(typ types.Type) {
+			dep, ok := typ.(*types.Named)
+			if !ok || dep.TypeArgs().Len() == 0 {
+				return
+			}
+			instantiation := &Instantiation{
+				Origin: dep.Origin().Obj(),
+				Type:   dep,
+			}
+			for argIdx := 0; argIdx < dep.TypeArgs().Len(); argIdx++ {
+				instantiation.TypeArgs = append(instantiation.TypeArgs, dep.TypeArgs().At(argIdx))
+			}
+			if !instantiation.IsConcrete() || result.find(instantiation) != nil {
+				return
+			}
+			result = append(result, instantiation)
+		})
+	}
+	return result
+}
+
+func (instantiations Instantiations) find(instantiation *Instantiation) *Instantiation {
+	for _, candidate := range instantiations {
+		if candidate.OriginPath() != instantiation.OriginPath() ||
+			candidate.Origin.Name() != instantiation.Origin.Name() ||
+			len(candidate.TypeArgs) != len(instantiation.TypeArgs) {
+			continue
+		}
+		identical := true
+		for idx := range candidate.TypeArgs {
+			// comparing strings instead of types.Identical, because
+			// the packages may be type-checked independently.
+			if candidate.TypeArgs[idx].String() != instantiation.TypeArgs[idx].String() {
+				identical = false
+				break
+			}
+		}
+		if identical {
+			return candidate
+		}
+	}
+	return nil
+}
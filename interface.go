@@ -0,0 +1,110 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// Interface represents one interface type of the source code file.
+type Interface struct {
+	AstTypeSpec
+}
+
+// Interfaces is a set of Interface-s
+type Interfaces []*Interface
+
+// String just implements fmt.Stringer
+func (iface Interface) String() string {
+	if iface.IsAnonymous() {
+		return "interface:" + iface.path()
+	}
+	return fmt.Sprintf("interface:%s", iface.Name())
+}
+
+// InterfaceType returns the AST of the interface type.
+func (iface Interface) InterfaceType() *ast.InterfaceType {
+	interfaceType, ok := iface.TypeSpec.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil
+	}
+	return interfaceType
+}
+
+// InterfaceMethod represents one method declared in an interface type.
+type InterfaceMethod struct {
+	*ast.Field
+	Interface *Interface
+}
+
+// InterfaceMethods is a set of InterfaceMethod-s.
+type InterfaceMethods []*InterfaceMethod
+
+// Name returns the name of the method.
+func (method InterfaceMethod) Name() string {
+	return method.Field.Names[0].Name
+}
+
+// FuncType returns the AST of the signature of the method.
+func (method InterfaceMethod) FuncType() *ast.FuncType {
+	return method.Field.Type.(*ast.FuncType)
+}
+
+// Object returns the type-checked method (or nil if there is no type
+// information).
+func (method InterfaceMethod) Object() *types.Func {
+	pkg := method.Interface.File.Package
+	if pkg == nil || pkg.Info == nil {
+		return nil
+	}
+	fn, _ := pkg.Info.Defs[method.Field.Names[0]].(*types.Func)
+	return fn
+}
+
+// ExplicitMethods returns the methods declared directly in the interface
+// type (methods of embedded interfaces are not included, see Embeddeds).
+func (iface *Interface) ExplicitMethods() InterfaceMethods {
+	interfaceType := iface.InterfaceType()
+	if interfaceType == nil || interfaceType.Methods == nil {
+		return nil
+	}
+	var result InterfaceMethods
+	for _, field := range interfaceType.Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); !ok || len(field.Names) == 0 {
+			continue
+		}
+		result = append(result, &InterfaceMethod{
+			Field:     field,
+			Interface: iface,
+		})
+	}
+	return result
+}
+
+// Embeddeds returns the type expressions of the embedded elements of
+// the interface (embedded interfaces and type unions of constraints).
+func (iface *Interface) Embeddeds() []ast.Expr {
+	interfaceType := iface.InterfaceType()
+	if interfaceType == nil || interfaceType.Methods == nil {
+		return nil
+	}
+	var result []ast.Expr
+	for _, field := range interfaceType.Methods.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		result = append(result, field.Type)
+	}
+	return result
+}
+
+// FindByName returns the method with the specified name (or nil if there is
+// no such method).
+func (methods InterfaceMethods) FindByName(name string) *InterfaceMethod {
+	for _, method := range methods {
+		if method.Name() == name {
+			return method
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package gosrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LayerRuleKind defines the kind of a layering constraint.
+type LayerRuleKind uint
+
+const (
+	// LayerRuleKindUndefined is an undefined kind of a rule.
+	LayerRuleKindUndefined = LayerRuleKind(iota)
+
+	// LayerRuleKindDeny forbids packages matching From to import packages
+	// matching To ("<from> may not import <to>").
+	LayerRuleKindDeny
+
+	// LayerRuleKindOnly forbids importing packages matching To by any
+	// packages except ones matching From ("only <from> may import <to>").
+	LayerRuleKindOnly
+)
+
+// String just implements fmt.Stringer
+func (kind LayerRuleKind) String() string {
+	switch kind {
+	case LayerRuleKindUndefined:
+		return "undefined"
+	case LayerRuleKindDeny:
+		return "deny"
+	case LayerRuleKindOnly:
+		return "only"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// LayerRule is one layering constraint on imports between packages.
+//
+// From and To are lists of package path patterns. A pattern matches
+// a package if the path elements of the pattern are a contiguous part
+// of the path of the package, which is either the end of the path or
+// is followed by subpackages. For example "internal/domain" matches
+// "example.com/app/internal/domain" and "example.com/app/internal/domain/user",
+// but not "example.com/app/internal/domainx". A trailing slash is ignored
+// ("cmd/" is the same as "cmd"). External test packages ("pkg_test") are
+// matched as their packages.
+type LayerRule struct {
+	Kind LayerRuleKind
+	From []string
+	To   []string
+
+	// Source is the text of the rule as it was written in the config.
+	Source string
+
+	// Filename and Line define where the rule is declared.
+	Filename string
+	Line     int
+}
+
+// LayerRules is a set of LayerRule-s.
+type LayerRules []*LayerRule
+
+// String just implements fmt.Stringer
+func (rule LayerRule) String() string {
+	return rule.Source
+}
+
+// LayerViolation is an import which violates a LayerRule.
+type LayerViolation struct {
+	Rule *LayerRule
+	Edge *ImportEdge
+}
+
+// LayerViolations is a set of LayerViolation-s.
+type LayerViolations []*LayerViolation
+
+// String just implements fmt.Stringer
+func (violation LayerViolation) String() string {
+	return fmt.Sprintf("%s: package '%s' imports '%s', which violates rule '%s' (%s:%d)",
+		violation.Edge.Position, violation.Edge.From, violation.Edge.To,
+		violation.Rule.Source, violation.Rule.Filename, violation.Rule.Line)
+}
+
+// LoadLayerRules reads layering rules from the file, see ParseLayerRules.
+func LoadLayerRules(path string) (LayerRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file '%s': %w", path, err)
+	}
+	defer f.Close()
+	return ParseLayerRules(f, path)
+}
+
+// ParseLayerRules parses layering rules. Each non-empty line is one rule
+// of one of the forms:
+//
+//	<from> may not import <to>
+//	only <from> may import <to>
+//
+// where <from> and <to> are comma-separated lists of package path patterns
+// (see LayerRule). Lines starting with "#" or "//" are comments. Examples:
+//
+//	# the domain knows nothing about the transport
+//	internal/domain may not import internal/transport, net/http
+//	only cmd/ may import flag
+//
+// The filename is used only for error messages and LayerRule.Filename.
+func ParseLayerRules(r io.Reader, filename string) (LayerRules, error) {
+	var result LayerRules
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rule, err := parseLayerRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: unable to parse rule <%s>: %w", filename, lineNum, line, err)
+		}
+		rule.Filename = filename
+		rule.Line = lineNum
+		result = append(result, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read rules from '%s': %w", filename, err)
+	}
+	return result, nil
+}
+
+func parseLayerRule(line string) (*LayerRule, error) {
+	rule := &LayerRule{Source: line}
+	var from, to string
+	switch {
+	case strings.HasPrefix(line, "only "):
+		parts := strings.SplitN(strings.TrimPrefix(line, "only "), " may import ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected 'only <from> may import <to>'")
+		}
+		rule.Kind = LayerRuleKindOnly
+		from, to = parts[0], parts[1]
+	default:
+		parts := strings.SplitN(line, " may not import ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected '<from> may not import <to>' or 'only <from> may import <to>'")
+		}
+		rule.Kind = LayerRuleKindDeny
+		from, to = parts[0], parts[1]
+	}
+
+	var err error
+	rule.From, err = parseLayerPatterns(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid <from>: %w", err)
+	}
+	rule.To, err = parseLayerPatterns(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid <to>: %w", err)
+	}
+	return rule, nil
+}
+
+func parseLayerPatterns(s string) ([]string, error) {
+	var result []string
+	for _, pattern := range strings.Split(s, ",") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("empty package pattern")
+		}
+		if strings.ContainsAny(pattern, " \t") {
+			return nil, fmt.Errorf("package pattern <%s> contains spaces", pattern)
+		}
+		result = append(result, pattern)
+	}
+	return result, nil
+}
+
+// matchLayerPattern returns true if the package path matches the pattern
+// (see LayerRule).
+func matchLayerPattern(pattern, pkgPath string) bool {
+	pkgPath = strings.TrimSuffix(pkgPath, `_test`)
+	pkgPath = "/" + pkgPath + "/"
+	return strings.Contains(pkgPath, "/"+pattern+"/")
+}
+
+func matchLayerPatterns(patterns []string, pkgPath string) bool {
+	for _, pattern := range patterns {
+		if matchLayerPattern(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsViolatedBy returns true if the import violates the rule.
+func (rule LayerRule) IsViolatedBy(edge *ImportEdge) bool {
+	switch rule.Kind {
+	case LayerRuleKindDeny:
+		return matchLayerPatterns(rule.From, edge.From) && matchLayerPatterns(rule.To, edge.To)
+	case LayerRuleKindOnly:
+		return matchLayerPatterns(rule.To, edge.To) && !matchLayerPatterns(rule.From, edge.From)
+	default:
+		return false
+	}
+}
+
+// Check returns all the imports of the graph which violate the rules
+// (in the order of the importing packages and positions of imports).
+// Only imports made by loaded packages are checked.
+func (rules LayerRules) Check(graph *ImportGraph) LayerViolations {
+	var result LayerViolations
+	for _, pkgPath := range graph.PackagePaths() {
+		for _, edge := range graph.ImportEdges(pkgPath) {
+			for _, rule := range rules {
+				if rule.IsViolatedBy(edge) {
+					result = append(result, &LayerViolation{
+						Rule: rule,
+						Edge: edge,
+					})
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// FieldLayout is the memory layout of one field of a structure (a field
+// declared like "A, B int" has two layouts).
+type FieldLayout struct {
+	// Var is the type-checked field.
+	Var *types.Var
+
+	// Field is the source code representation of the field (it could be
+	// nil for structures without source code, see LayoutOf).
+	Field *Field
+
+	Offset int64
+	Size   int64
+	Align  int64
+
+	// Padding is the amount of bytes between the field and the next one
+	// (or the end of the structure).
+	Padding int64
+}
+
+// FieldLayouts is a set of FieldLayout-s.
+type FieldLayouts []*FieldLayout
+
+// Name returns the name of the field.
+func (field FieldLayout) Name() string {
+	return field.Var.Name()
+}
+
+// String just implements fmt.Stringer
+func (field FieldLayout) String() string {
+	return fmt.Sprintf("%s: offset %d, size %d, align %d, padding %d",
+		field.Name(), field.Offset, field.Size, field.Align, field.Padding)
+}
+
+// Names returns the names of the fields.
+func (fields FieldLayouts) Names() []string {
+	var result []string
+	for _, field := range fields {
+		result = append(result, field.Name())
+	}
+	return result
+}
+
+// FindByName returns the field with the specified name (or nil if there
+// is no such field).
+func (fields FieldLayouts) FindByName(name string) *FieldLayout {
+	for _, field := range fields {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// StructLayout is the memory layout of a structure for a GOARCH.
+type StructLayout struct {
+	// Struct is the structure (it could be nil, see LayoutOf).
+	Struct *Struct
+
+	GOARCH string
+	Size   int64
+	Align  int64
+	Fields FieldLayouts
+
+	sizes types.Sizes
+}
+
+// Layout returns the memory layout of the structure for the GOARCH (like
+// "amd64" or "386") as it is produced by the gc compiler.
+//
+// Requires type information; generic structures are not supported.
+func (_struct *Struct) Layout(goarch string) (*StructLayout, error) {
+	if _struct.IsGeneric() {
+		return nil, fmt.Errorf("the layout of generic %s depends on type arguments", _struct)
+	}
+	typ := _struct.Type()
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for %s", _struct)
+	}
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+	layout, err := LayoutOf(typ, goarch)
+	if err != nil {
+		return nil, err
+	}
+	layout.Struct = _struct
+	for idx, field := range layout.Fields {
+		field.Field = fields.findByFieldIndex(idx)
+	}
+	return layout, nil
+}
+
+// LayoutOf returns the memory layout of the type (with a structure as
+// the underlying type) for the GOARCH, see Struct.Layout.
+func LayoutOf(typ types.Type, goarch string) (*StructLayout, error) {
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct, but %T", typ, typ.Underlying())
+	}
+	sizes := types.SizesFor("gc", goarch)
+	if sizes == nil {
+		return nil, fmt.Errorf("unknown GOARCH '%s'", goarch)
+	}
+	var vars []*types.Var
+	for idx := 0; idx < structType.NumFields(); idx++ {
+		vars = append(vars, structType.Field(idx))
+	}
+	return newStructLayout(vars, goarch, sizes), nil
+}
+
+func newStructLayout(vars []*types.Var, goarch string, sizes types.Sizes) *StructLayout {
+	structType := types.NewStruct(vars, nil)
+	layout := &StructLayout{
+		GOARCH: goarch,
+		Size:   sizes.Sizeof(structType),
+		Align:  sizes.Alignof(structType),
+		sizes:  sizes,
+	}
+	offsets := sizes.Offsetsof(vars)
+	for idx, v := range vars {
+		layout.Fields = append(layout.Fields, &FieldLayout{
+			Var:    v,
+			Offset: offsets[idx],
+			Size:   sizes.Sizeof(v.Type()),
+			Align:  sizes.Alignof(v.Type()),
+		})
+	}
+	for idx, field := range layout.Fields {
+		end := layout.Size
+		if idx+1 < len(layout.Fields) {
+			end = layout.Fields[idx+1].Offset
+		}
+		field.Padding = end - field.Offset - field.Size
+	}
+	return layout
+}
+
+// Padding returns the total amount of padding bytes of the structure.
+func (layout StructLayout) Padding() int64 {
+	var result int64
+	for _, field := range layout.Fields {
+		result += field.Padding
+	}
+	return result
+}
+
+// OptimalOrder returns the fields in the order, which minimizes
+// the padding: zero-sized fields first (a zero-sized field in the end
+// of a structure is padded), then by alignment from the largest
+// to the smallest. Otherwise the original order is kept.
+func (layout StructLayout) OptimalOrder() FieldLayouts {
+	result := append(FieldLayouts{}, layout.Fields...)
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if (a.Size == 0) != (b.Size == 0) {
+			return a.Size == 0
+		}
+		return a.Align > b.Align
+	})
+	return result
+}
+
+// OptimalLayout returns the layout of the structure with the fields
+// reordered by OptimalOrder.
+func (layout StructLayout) OptimalLayout() *StructLayout {
+	order := layout.OptimalOrder()
+	var vars []*types.Var
+	for _, field := range order {
+		vars = append(vars, field.Var)
+	}
+	result := newStructLayout(vars, layout.GOARCH, layout.sizes)
+	result.Struct = layout.Struct
+	for idx, field := range result.Fields {
+		field.Field = order[idx].Field
+	}
+	return result
+}
+
+// IsOptimal returns true if reordering the fields cannot reduce the size
+// of the structure.
+func (layout StructLayout) IsOptimal() bool {
+	return layout.OptimalLayout().Size >= layout.Size
+}
+
+// String just implements fmt.Stringer
+func (layout StructLayout) String() string {
+	var result strings.Builder
+	name := "struct"
+	if layout.Struct != nil {
+		name = layout.Struct.String()
+	}
+	fmt.Fprintf(&result, "%s (%s): size %d, align %d, padding %d\n",
+		name, layout.GOARCH, layout.Size, layout.Align, layout.Padding())
+	for _, field := range layout.Fields {
+		fmt.Fprintf(&result, "\t%s\n", field)
+	}
+	return result.String()
+}
@@ -0,0 +1,414 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultLayoutGOARCHes are the GOARCHes compared by CompareLayouts and
+// VerifyLayout if no GOARCH is specified.
+var DefaultLayoutGOARCHes = []string{"amd64", "386", "arm", "arm64"}
+
+// LayoutDirectiveName is the name of the directive declaring the expected
+// layout of a structure or of its field, for example:
+//
+//	//gosrc:layout goarch=amd64,arm64 size=24 align=8
+//	//gosrc:layout portable
+//	type Header struct {
+//		//gosrc:layout offset=8 size=8
+//		Length uint64
+//	}
+//
+// Options "size", "align" and "offset" (only for fields) are the expected
+// values, option "goarch" limits the GOARCHes to check (the default is
+// DefaultLayoutGOARCHes) and flag "portable" requires the layout to be
+// the same for all the GOARCHes.
+const LayoutDirectiveName = "gosrc:layout"
+
+// FieldLayoutComparison is the memory layouts of a field of a structure
+// for multiple GOARCHes.
+type FieldLayoutComparison struct {
+	Var *types.Var
+
+	// Field is the source code representation of the field (it could be
+	// nil, see FieldLayout).
+	Field *Field
+
+	// Layouts are the layouts of the field in the order of
+	// LayoutComparison.GOARCHes.
+	Layouts FieldLayouts
+
+	// PlatformDependent is true if the size or the alignment of the type
+	// differs between the compared GOARCHes (see IsPlatformDependent).
+	PlatformDependent bool
+}
+
+// FieldLayoutComparisons is a set of FieldLayoutComparison-s.
+type FieldLayoutComparisons []*FieldLayoutComparison
+
+// Name returns the name of the field.
+func (field FieldLayoutComparison) Name() string {
+	return field.Var.Name()
+}
+
+// IsConsistent returns true if the offset and the size of the field are
+// the same for all the GOARCHes.
+func (field FieldLayoutComparison) IsConsistent() bool {
+	for _, layout := range field.Layouts[1:] {
+		if layout.Offset != field.Layouts[0].Offset || layout.Size != field.Layouts[0].Size {
+			return false
+		}
+	}
+	return true
+}
+
+// FindByName returns the field with the specified name (or nil if there
+// is no such field).
+func (fields FieldLayoutComparisons) FindByName(name string) *FieldLayoutComparison {
+	for _, field := range fields {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// FilterInconsistent returns only the fields with different offsets or
+// sizes for different GOARCHes.
+func (fields FieldLayoutComparisons) FilterInconsistent() FieldLayoutComparisons {
+	var result FieldLayoutComparisons
+	for _, field := range fields {
+		if !field.IsConsistent() {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// LayoutComparison is the memory layouts of a structure for multiple
+// GOARCHes.
+type LayoutComparison struct {
+	Struct   *Struct
+	GOARCHes []string
+
+	// Layouts are the layouts of the structure in the order of GOARCHes.
+	Layouts []*StructLayout
+
+	Fields FieldLayoutComparisons
+}
+
+// CompareLayouts returns the memory layouts of the structure for
+// the GOARCHes (DefaultLayoutGOARCHes if none is specified).
+func (_struct *Struct) CompareLayouts(goarches ...string) (*LayoutComparison, error) {
+	if len(goarches) == 0 {
+		goarches = DefaultLayoutGOARCHes
+	}
+	result := &LayoutComparison{
+		Struct:   _struct,
+		GOARCHes: goarches,
+	}
+	for _, goarch := range goarches {
+		layout, err := _struct.Layout(goarch)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the layout of %s for %s: %w", _struct, goarch, err)
+		}
+		result.Layouts = append(result.Layouts, layout)
+	}
+	for idx, field := range result.Layouts[0].Fields {
+		comparison := &FieldLayoutComparison{
+			Var:               field.Var,
+			Field:             field.Field,
+			PlatformDependent: IsPlatformDependent(field.Var.Type(), goarches...),
+		}
+		for _, layout := range result.Layouts {
+			comparison.Layouts = append(comparison.Layouts, layout.Fields[idx])
+		}
+		result.Fields = append(result.Fields, comparison)
+	}
+	return result, nil
+}
+
+// IsConsistent returns true if the size and the alignment of the structure
+// and the offsets and sizes of all its fields are the same for all
+// the GOARCHes.
+func (comparison LayoutComparison) IsConsistent() bool {
+	for _, layout := range comparison.Layouts[1:] {
+		if layout.Size != comparison.Layouts[0].Size || layout.Align != comparison.Layouts[0].Align {
+			return false
+		}
+	}
+	return len(comparison.Fields.FilterInconsistent()) == 0
+}
+
+// String just implements fmt.Stringer
+//
+// It returns a table with "offset:size" of each field for each GOARCH.
+func (comparison LayoutComparison) String() string {
+	var result strings.Builder
+	status := "same layout"
+	if !comparison.IsConsistent() {
+		status = "layout differs"
+	}
+	fmt.Fprintf(&result, "%s: %s\n", comparison.Struct, status)
+
+	w := tabwriter.NewWriter(&result, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "FIELD\tTYPE\t%s\t\n", strings.Join(comparison.GOARCHes, "\t"))
+	for _, field := range comparison.Fields {
+		var cells []string
+		for _, layout := range field.Layouts {
+			cells = append(cells, fmt.Sprintf("%d:%d", layout.Offset, layout.Size))
+		}
+		var notes []string
+		if field.PlatformDependent {
+			notes = append(notes, "platform-dependent")
+		}
+		if !field.IsConsistent() {
+			notes = append(notes, "differs")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			field.Name(), types.TypeString(field.Var.Type(), (*types.Package).Name),
+			strings.Join(cells, "\t"), strings.Join(notes, ", "))
+	}
+	var cells []string
+	for _, layout := range comparison.Layouts {
+		cells = append(cells, fmt.Sprintf("%d/%d", layout.Size, layout.Align))
+	}
+	fmt.Fprintf(w, "(size/align)\t\t%s\t\n", strings.Join(cells, "\t"))
+	w.Flush()
+
+	// tabwriter pads the last cells
+	lines := strings.Split(result.String(), "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsPlatformDependent returns true if the size or the alignment of
+// the type differs between the GOARCHes (DefaultLayoutGOARCHes if none is
+// specified), like for int, pointers and slices, but also for uint64
+// (which is 4-byte aligned on 386 and arm). Unknown GOARCHes are ignored.
+func IsPlatformDependent(typ types.Type, goarches ...string) bool {
+	if len(goarches) == 0 {
+		goarches = DefaultLayoutGOARCHes
+	}
+	var first types.Sizes
+	for _, goarch := range goarches {
+		sizes := types.SizesFor("gc", goarch)
+		if sizes == nil {
+			continue
+		}
+		if first == nil {
+			first = sizes
+			continue
+		}
+		if sizes.Sizeof(typ) != first.Sizeof(typ) || sizes.Alignof(typ) != first.Alignof(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutDirective is the content of a LayoutDirectiveName directive.
+type layoutDirective struct {
+	GOARCH   []string `directive:"goarch"`
+	Size     int64
+	Align    int64
+	Offset   int64
+	Portable bool
+}
+
+func parseLayoutDirective(directive *Directive) (*layoutDirective, error) {
+	result := &layoutDirective{
+		Size:   -1,
+		Align:  -1,
+		Offset: -1,
+	}
+	if err := directive.Unmarshal(result); err != nil {
+		return nil, err
+	}
+	if len(result.GOARCH) == 0 {
+		result.GOARCH = DefaultLayoutGOARCHes
+	}
+	for _, goarch := range result.GOARCH {
+		if types.SizesFor("gc", goarch) == nil {
+			return nil, directive.errorf("unknown GOARCH '%s'", goarch)
+		}
+	}
+	return result, nil
+}
+
+// LayoutMismatch is a difference between the actual memory layout of
+// a structure (or of its field) and the layout declared by
+// a LayoutDirectiveName directive.
+type LayoutMismatch struct {
+	Position  token.Position
+	Directive *Directive
+	Struct    *Struct
+
+	// Field is nil if the mismatch is about the structure itself.
+	Field *Field
+
+	// GOARCH is empty for mismatches of option "portable".
+	GOARCH string
+
+	// Property is "size", "align", "offset" or "portable".
+	Property string
+
+	// Expected and Actual are the values of the property (if applicable).
+	Expected int64
+	Actual   int64
+
+	Message string
+}
+
+// LayoutMismatches is a set of LayoutMismatch-es.
+type LayoutMismatches []*LayoutMismatch
+
+// String just implements fmt.Stringer
+func (mismatch LayoutMismatch) String() string {
+	return fmt.Sprintf("%s: %s", mismatch.Position, mismatch.Message)
+}
+
+// VerifyLayout checks the memory layout of the structure against
+// the layout declared by LayoutDirectiveName directives of the structure
+// and of its fields. It returns nil if there are no such directives.
+func (_struct *Struct) VerifyLayout() (LayoutMismatches, error) {
+	directives, err := _struct.Directives()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+
+	layouts := map[string]*StructLayout{}
+	getLayout := func(goarch string) (*StructLayout, error) {
+		if layout, ok := layouts[goarch]; ok {
+			return layout, nil
+		}
+		layout, err := _struct.Layout(goarch)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the layout of %s for %s: %w", _struct, goarch, err)
+		}
+		layouts[goarch] = layout
+		return layout, nil
+	}
+
+	var result LayoutMismatches
+	check := func(field *Field, directive *Directive) error {
+		expected, err := parseLayoutDirective(directive)
+		if err != nil {
+			return err
+		}
+		subject := _struct.String()
+		if field != nil {
+			subject = fmt.Sprintf("%s.%s", _struct, field.Name())
+		} else if expected.Offset >= 0 {
+			return directive.errorf("option 'offset' is applicable only to fields")
+		}
+		report := func(goarch, property string, expectedValue, actualValue int64, message string) {
+			result = append(result, &LayoutMismatch{
+				Position:  directive.Position,
+				Directive: directive,
+				Struct:    _struct,
+				Field:     field,
+				GOARCH:    goarch,
+				Property:  property,
+				Expected:  expectedValue,
+				Actual:    actualValue,
+				Message:   fmt.Sprintf("%s: %s", subject, message),
+			})
+		}
+		var first *FieldLayout
+		var firstGOARCH string
+		var firstLayout *StructLayout
+		for _, goarch := range expected.GOARCH {
+			layout, err := getLayout(goarch)
+			if err != nil {
+				return err
+			}
+			size, align, offset := layout.Size, layout.Align, int64(-1)
+			var fieldLayout *FieldLayout
+			if field != nil {
+				fieldLayout = layout.fieldLayoutOf(field)
+				if fieldLayout == nil {
+					return fmt.Errorf("no layout of field %s", subject)
+				}
+				size, align, offset = fieldLayout.Size, fieldLayout.Align, fieldLayout.Offset
+			}
+			for _, property := range []struct {
+				name     string
+				expected int64
+				actual   int64
+			}{
+				{"size", expected.Size, size},
+				{"align", expected.Align, align},
+				{"offset", expected.Offset, offset},
+			} {
+				if property.expected < 0 || property.expected == property.actual {
+					continue
+				}
+				report(goarch, property.name, property.expected, property.actual,
+					fmt.Sprintf("%s is %d on %s, expected %d", property.name, property.actual, goarch, property.expected))
+			}
+			if !expected.Portable {
+				continue
+			}
+			switch {
+			case firstLayout == nil:
+				first, firstGOARCH, firstLayout = fieldLayout, goarch, layout
+			case field == nil && (layout.Size != firstLayout.Size || layout.Align != firstLayout.Align):
+				report("", "portable", 0, 0, fmt.Sprintf("the layout is not portable: size/align is %d/%d on %s, but %d/%d on %s",
+					firstLayout.Size, firstLayout.Align, firstGOARCH, layout.Size, layout.Align, goarch))
+			case field != nil && (fieldLayout.Offset != first.Offset || fieldLayout.Size != first.Size):
+				report("", "portable", 0, 0, fmt.Sprintf("the layout is not portable: offset:size is %d:%d on %s, but %d:%d on %s",
+					first.Offset, first.Size, firstGOARCH, fieldLayout.Offset, fieldLayout.Size, goarch))
+			}
+		}
+		if field == nil && expected.Portable {
+			// the whole structure is portable only if all its fields are
+			comparison, err := _struct.CompareLayouts(expected.GOARCH...)
+			if err != nil {
+				return err
+			}
+			for _, inconsistent := range comparison.Fields.FilterInconsistent() {
+				report("", "portable", 0, 0, fmt.Sprintf("the layout is not portable: field %s has different offsets or sizes", inconsistent.Name()))
+			}
+		}
+		return nil
+	}
+
+	for _, directive := range directives.FilterByName(LayoutDirectiveName) {
+		if err := check(nil, directive); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range fields {
+		fieldDirectives, err := field.Directives()
+		if err != nil {
+			return nil, err
+		}
+		for _, directive := range fieldDirectives.FilterByName(LayoutDirectiveName) {
+			if err := check(field, directive); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// fieldLayoutOf returns the layout of the field (of the first name for
+// fields like "A, B int").
+func (layout StructLayout) fieldLayoutOf(field *Field) *FieldLayout {
+	for _, fieldLayout := range layout.Fields {
+		if fieldLayout.Field != nil && fieldLayout.Field.Index == field.Index {
+			return fieldLayout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestCompareLayouts(t *testing.T) {
+	pkg := openTestPackage(t, "layoutcompat")
+
+	comparison, err := findStruct(t, pkg, "Header").CompareLayouts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"amd64", "386", "arm", "arm64"}, comparison.GOARCHes)
+	require.False(t, comparison.IsConsistent())
+	length := comparison.Fields.FindByName("Length")
+	require.False(t, length.IsConsistent())
+	require.True(t, length.PlatformDependent)
+	require.Equal(t, int64(4), length.Layouts[1].Offset)
+	require.Equal(t, []string{"Length"}, namesOfComparisons(comparison.Fields.FilterInconsistent()))
+	require.Contains(t, comparison.String(), "differs")
+
+	comparison, err = findStruct(t, pkg, "Message").CompareLayouts("amd64", "386")
+	require.NoError(t, err)
+	require.True(t, comparison.Fields.FindByName("ID").PlatformDependent)
+	require.True(t, comparison.Fields.FindByName("Data").PlatformDependent)
+	require.Contains(t, comparison.String(), "platform-dependent")
+
+	comparison, err = findStruct(t, pkg, "Portable").CompareLayouts()
+	require.NoError(t, err)
+	require.True(t, comparison.IsConsistent())
+	for _, field := range comparison.Fields {
+		require.False(t, field.PlatformDependent, field.Name())
+	}
+}
+
+func namesOfComparisons(fields gosrc.FieldLayoutComparisons) []string {
+	var result []string
+	for _, field := range fields {
+		result = append(result, field.Name())
+	}
+	return result
+}
+
+func TestVerifyLayout(t *testing.T) {
+	pkg := openTestPackage(t, "layoutcompat")
+
+	mismatches, err := findStruct(t, pkg, "Header").VerifyLayout()
+	require.NoError(t, err)
+	var properties []string
+	for _, mismatch := range mismatches {
+		properties = append(properties, mismatch.GOARCH+"/"+mismatch.Property)
+	}
+	require.Equal(t, []string{
+		"386/size", "386/align", "/portable",
+		"arm/size", "arm/align", "/portable",
+		"/portable",
+		"386/offset", "arm/offset",
+	}, properties)
+	require.Contains(t, mismatches[0].String(), "struct:Header: size is 12 on 386, expected 16")
+
+	for _, name := range []string{"Portable", "Message"} {
+		mismatches, err = findStruct(t, pkg, name).VerifyLayout()
+		require.NoError(t, err)
+		require.Empty(t, mismatches, name)
+	}
+
+	_, err = findStruct(t, pkg, "Broken").VerifyLayout()
+	require.Error(t, err)
+}
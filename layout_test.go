@@ -0,0 +1,77 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructLayout(t *testing.T) {
+	pkg := openTestPackage(t, "layout")
+	bad := findStruct(t, pkg, "Bad")
+
+	layout, err := bad.Layout("amd64")
+	require.NoError(t, err)
+	require.Equal(t, int64(32), layout.Size)
+	require.Equal(t, int64(8), layout.Align)
+	require.Equal(t, int64(18), layout.Padding())
+	b := layout.Fields.FindByName("B")
+	require.Equal(t, int64(8), b.Offset)
+	require.Equal(t, "B", b.Field.Name())
+	require.Equal(t, int64(7), layout.Fields.FindByName("A").Padding)
+	require.False(t, layout.IsOptimal())
+
+	require.Equal(t, []string{"E", "B", "D", "A", "C"}, layout.OptimalOrder().Names())
+	optimal := layout.OptimalLayout()
+	require.Equal(t, int64(16), optimal.Size)
+	require.Equal(t, int64(2), optimal.Padding())
+
+	layout, err = bad.Layout("386")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), layout.Fields.FindByName("B").Offset)
+
+	good, err := findStruct(t, pkg, "Good").Layout("arm64")
+	require.NoError(t, err)
+	require.True(t, good.IsOptimal())
+
+	_, err = bad.Layout("unknown")
+	require.Error(t, err)
+}
+
+func TestReorderFields(t *testing.T) {
+	pkg := openTestPackage(t, "layout")
+
+	edit, err := findStruct(t, pkg, "Bad").ReorderFieldsOptimally("amd64")
+	require.NoError(t, err)
+	require.True(t, edit.IsChanged())
+	require.Contains(t, string(edit.Modified), `type Bad struct {
+	E struct{}
+	B int64
+	D int32
+	// A is a flag.
+	A bool // first
+	C bool
+}`)
+	require.Contains(t, edit.Diff(), "-\tE struct{}\n")
+	require.Contains(t, string(edit.Modified), "var Unformatted = []int{1,2,3}\n")
+
+	edit, err = findStruct(t, pkg, "Good").ReorderFieldsOptimally("amd64")
+	require.NoError(t, err)
+	require.False(t, edit.IsChanged())
+	require.Empty(t, edit.Diff())
+
+	grouped := findStruct(t, pkg, "Grouped")
+	edit, err = grouped.ReorderFields([]string{"C", "A", "B"})
+	require.NoError(t, err)
+	require.Contains(t, string(edit.Modified), `type Grouped struct {
+	// C is a counter.
+	C int64 `+"`json:\"c\"`"+` // in bytes
+
+	A, B bool
+}`)
+
+	_, err = grouped.ReorderFields([]string{"A", "C", "B"})
+	require.Error(t, err)
+	_, err = grouped.ReorderFields([]string{"A", "B"})
+	require.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Loader opens packages on demand (by their Go pkg paths) and caches them.
+// It allows to resolve types from one package to their source code
+// in other packages.
+//
+// Loader is not safe for concurrent use.
+type Loader struct {
+	BuildContext     *build.Context
+	ExternalImporter Importer
+
+	packages    map[string]*Package
+	symbolIndex *SymbolIndex
+}
+
+// NewLoader returns a new instance of Loader.
+func NewLoader(buildCtx *build.Context, externalImporter Importer) *Loader {
+	return &Loader{
+		BuildContext:     buildCtx,
+		ExternalImporter: externalImporter,
+		packages:         map[string]*Package{},
+	}
+}
+
+// AddDirectory registers already opened packages, so they will not be
+// opened again.
+func (loader *Loader) AddDirectory(dir *Directory) {
+	for _, pkg := range dir.Packages {
+		if strings.HasSuffix(pkg.Name, `_test`) {
+			continue
+		}
+		loader.packages[pkg.Path()] = pkg
+	}
+	loader.symbolIndex = nil
+}
+
+// Load returns the package (excluding tests) with the specified Go pkg
+// path, opening it if it was not opened yet.
+func (loader *Loader) Load(pkgPath string) (*Package, error) {
+	if pkg, ok := loader.packages[pkgPath]; ok {
+		return pkg, nil
+	}
+
+	dir, err := OpenDirectoryByPkgPath(loader.BuildContext, pkgPath, false, false, false, loader.ExternalImporter)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open package '%s': %w", pkgPath, err)
+	}
+	for _, pkg := range dir.Packages {
+		if strings.HasSuffix(pkg.Name, `_test`) {
+			continue
+		}
+		loader.packages[pkgPath] = pkg
+		loader.symbolIndex = nil
+		return pkg, nil
+	}
+	return nil, ErrPackageNotFound{GoPath: pkgPath}
+}
+
+// Packages returns all the packages opened (or added) so far, sorted
+// by their paths.
+func (loader *Loader) Packages() Packages {
+	var result Packages
+	for _, pkg := range loader.packages {
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path() < result[j].Path()
+	})
+	return result
+}
+
+// LookupTypeSpec returns the definition of the type with the specified
+// package path and name, opening the package if required.
+//
+// Use AstTypeSpec.ToStruct and AstTypeSpec.ToInterface to get more
+// specific entities.
+func (loader *Loader) LookupTypeSpec(pkgPath, name string) (*AstTypeSpec, error) {
+	pkg, err := loader.Load(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	astTypeSpec := pkg.AstTypeSpecs().FindByName(name)
+	if astTypeSpec == nil {
+		return nil, fmt.Errorf("type '%s' is not found in package '%s'", name, pkgPath)
+	}
+	return astTypeSpec, nil
+}
+
+// ResolveNamed returns the definition of the named type (instantiated
+// generic types are resolved to the generic type definition).
+func (loader *Loader) ResolveNamed(named *types.Named) (*AstTypeSpec, error) {
+	obj := named.Origin().Obj()
+	if obj.Pkg() == nil {
+		return nil, fmt.Errorf("type '%s' is predeclared and has no source code", obj.Name())
+	}
+	if obj.Parent() != nil && obj.Parent() != obj.Pkg().Scope() {
+		return nil, fmt.Errorf("type '%s' is declared inside of a function and is not supported", obj.Name())
+	}
+	return loader.LookupTypeSpec(obj.Pkg().Path(), obj.Name())
+}
+
+// ResolveType returns the definition of the type of items referenced by
+// the field (see ItemTypeName), opening the package where it is defined
+// if required. For example for a field of type "[]*pkg.Value" it returns
+// the definition of "Value" from package "pkg".
+//
+// If loader is nil, then only types of the package of the structure
+// could be resolved.
+func (field Field) ResolveType(loader *Loader) (*AstTypeSpec, error) {
+	item := field.TypeRef().Item()
+	named, ok := types.Unalias(item.Type).(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("type '%s' of field '%s' is not a named type", item, field.Name())
+	}
+
+	obj := named.Origin().Obj()
+	if pkg := field.Struct.File.Package; obj.Pkg() != nil && obj.Pkg().Path() == pkg.Path() {
+		if astTypeSpec := pkg.AstTypeSpecs().FindByName(obj.Name()); astTypeSpec != nil {
+			return astTypeSpec, nil
+		}
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("type '%s' is defined in another package, a Loader is required", item)
+	}
+	return loader.ResolveNamed(named)
+}
+
+// DeclarationOf returns the declaration of the object used (or defined)
+// by the identifier of the package, opening the package where the object
+// is declared if required. See also Package.DeclarationOf.
+func (loader *Loader) DeclarationOf(pkg *Package, ident *ast.Ident) (*Declaration, error) {
+	decl, err := pkg.DeclarationOf(ident, loader.Packages()...)
+	if err != nil {
+		return nil, err
+	}
+	if decl.Package != nil || !decl.Position.IsValid() || decl.Object.Pkg() == nil {
+		return decl, nil
+	}
+
+	declPkg, err := loader.Load(decl.Object.Pkg().Path())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the package of '%s': %w", decl.Object, err)
+	}
+	return pkg.DeclarationOf(ident, declPkg)
+}
@@ -0,0 +1,213 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// Method represents one method of a method set of a type.
+type Method struct {
+	// Selection is the method as it is selected from the type (it contains
+	// the path through embedded fields for promoted methods).
+	Selection *types.Selection
+
+	// Func is the source code of the method. It is nil if the source code
+	// is not available (the method is declared in a package which is not
+	// loaded, or the method is a method of an interface).
+	Func *Func
+}
+
+// Methods is a set of Method-s.
+type Methods []*Method
+
+// Object returns the type-checked method.
+func (method Method) Object() *types.Func {
+	return method.Selection.Obj().(*types.Func)
+}
+
+// Name returns the name of the method.
+func (method Method) Name() string {
+	return method.Selection.Obj().Name()
+}
+
+// String just implements fmt.Stringer
+func (method Method) String() string {
+	return method.Selection.String()
+}
+
+// IsPromoted returns true if the method is promoted through an embedded
+// field.
+func (method Method) IsPromoted() bool {
+	return len(method.Selection.Index()) > 1
+}
+
+// IsPointerReceiver returns true if the method is declared with
+// a pointer receiver.
+func (method Method) IsPointerReceiver() bool {
+	recv := method.Object().Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	_, ok := recv.Type().(*types.Pointer)
+	return ok
+}
+
+// IsInterfaceMethod returns true if the method is a method of
+// an interface (including interfaces embedded into structures).
+func (method Method) IsInterfaceMethod() bool {
+	recv := method.Object().Type().(*types.Signature).Recv()
+	if recv == nil {
+		return true
+	}
+	return types.IsInterface(recv.Type())
+}
+
+// MethodSetOf returns the method set of the type (according to the rules
+// of types.NewMethodSet: for example, the method set of a value type does
+// not include methods with pointer receivers). Methods are mapped to
+// the source code (see Method.Func) using the specified packages.
+func MethodSetOf(typ types.Type, pkgs Packages) Methods {
+	methodSet := types.NewMethodSet(typ)
+	var result Methods
+	for idx := 0; idx < methodSet.Len(); idx++ {
+		selection := methodSet.At(idx)
+		method := &Method{
+			Selection: selection,
+		}
+		if fn, ok := selection.Obj().(*types.Func); ok {
+			method.Func = pkgs.FindFunc(fn)
+		}
+		result = append(result, method)
+	}
+	return result
+}
+
+// MethodSetOf returns the method set of the type (see the function
+// MethodSetOf), mapping methods to the source code in the packages opened
+// by the loader (packages of methods are opened if required).
+func (loader *Loader) MethodSetOf(typ types.Type) (Methods, error) {
+	return methodSetOf(typ, nil, loader)
+}
+
+// methodSetOf returns the method set of the type, mapping methods to
+// the source code in the package or (if it is not found there and
+// the loader is not nil) in the packages opened by the loader.
+func methodSetOf(typ types.Type, pkg *Package, loader *Loader) (Methods, error) {
+	methods := MethodSetOf(typ, Packages{pkg})
+	if loader == nil {
+		return methods, nil
+	}
+	for _, method := range methods {
+		if method.Func != nil || method.IsInterfaceMethod() {
+			continue
+		}
+		methodPkg := method.Object().Pkg()
+		if methodPkg == nil {
+			continue
+		}
+		loaded, err := loader.Load(methodPkg.Path())
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the package of method '%s': %w", method.Name(), err)
+		}
+		method.Func = Packages{loaded}.FindFunc(method.Object())
+	}
+	return methods, nil
+}
+
+// MethodSet returns the method set of the type. If pointerReceiver is true
+// then the method set of the pointer to the type is returned (which also
+// includes methods with pointer receivers).
+//
+// Methods are mapped to the source code (see Method.Func) in the package
+// of the type and, if the loader is not nil, in the packages opened by
+// the loader (for methods promoted from types of other packages).
+//
+// Requires type information (the package should not be opened with
+// "onlyFiles").
+func (astTypeSpec AstTypeSpec) MethodSet(pointerReceiver bool, loader *Loader) (Methods, error) {
+	typ := astTypeSpec.Type()
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for type '%s'", astTypeSpec.Name())
+	}
+	if pointerReceiver && !types.IsInterface(typ) {
+		typ = types.NewPointer(typ)
+	}
+	return methodSetOf(typ, astTypeSpec.File.Package, loader)
+}
+
+// MethodSet returns the method set of the type of the value of the field.
+// If the type is not a pointer nor an interface, then methods with pointer
+// receivers are also included (the method set of the pointer to the type
+// is returned), as Field.Methods always did. Note that they could not be
+// called on a field of a non-addressable structure value.
+//
+// See AstTypeSpec.MethodSet about the loader (could be nil).
+func (field Field) MethodSet(loader *Loader) (Methods, error) {
+	typ := field.TypeValue.Type
+	if typ == nil {
+		return nil, nil
+	}
+	if _, ok := typ.(*types.Pointer); !ok && !types.IsInterface(typ) {
+		typ = types.NewPointer(typ)
+	}
+	return methodSetOf(typ, field.Struct.File.Package, loader)
+}
+
+// Funcs returns the source code of the methods (methods without
+// the source code available are skipped).
+func (methods Methods) Funcs() Funcs {
+	var result Funcs
+	for _, method := range methods {
+		if method.Func == nil {
+			continue
+		}
+		result = append(result, method.Func)
+	}
+	return result
+}
+
+// FindByName returns the method with the specified name (or nil if there is
+// no such method).
+func (methods Methods) FindByName(name string) *Method {
+	for _, method := range methods {
+		if method.Name() == name {
+			return method
+		}
+	}
+	return nil
+}
+
+// FindFunc returns the source code of the function or method (or nil if
+// it is not found in the packages).
+func (pkgs Packages) FindFunc(fn *types.Func) *Func {
+	fn = fn.Origin()
+	if fn.Pkg() == nil {
+		return nil
+	}
+
+	recvTypeName := ""
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		recvType := recv.Type()
+		if pointer, ok := recvType.(*types.Pointer); ok {
+			recvType = pointer.Elem()
+		}
+		named, ok := recvType.(*types.Named)
+		if !ok {
+			// a method of an interface
+			return nil
+		}
+		recvTypeName = named.Obj().Name()
+	}
+
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Path() != fn.Pkg().Path() {
+			continue
+		}
+		for _, candidate := range pkg.Funcs().FindByName(fn.Name()) {
+			if candidate.ReceiverTypeName() == recvTypeName {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
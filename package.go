@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/token"
 	"go/types"
 	"path/filepath"
 	"strings"
@@ -16,6 +17,7 @@ type Package struct {
 	Name       string
 	LookupPath string
 	DirPath    string
+	FileSet    *token.FileSet
 	Info       *types.Info
 	Files      Files
 }
@@ -31,10 +33,116 @@ func (pkg *Package) String() string {
 // ToType returns types.TypeAndValue for a specified type expression
 // (if one was parsed).
 func (pkg *Package) ToType(expr ast.Expr) (types.TypeAndValue, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return types.TypeAndValue{}, err
+	}
+	typeAndValue, ok := pkg.Info.Types[expr]
+	if !ok {
+		return types.TypeAndValue{}, fmt.Errorf("no type information for expression %T at %s", expr, pkg.position(expr.Pos()))
+	}
+	return typeAndValue, nil
+}
+
+func (pkg *Package) checkInfo() error {
 	if pkg == nil {
-		return types.TypeAndValue{}, fmt.Errorf("got: Package == nil")
+		return fmt.Errorf("got: Package == nil")
+	}
+	if pkg.Info == nil {
+		return fmt.Errorf("package '%s' has no type information (opened with onlyFiles?)", pkg.Name)
+	}
+	return nil
+}
+
+// position returns the position in the source code (or an invalid position
+// if it is unknown).
+func (pkg *Package) position(pos token.Pos) token.Position {
+	if pkg == nil || pkg.FileSet == nil {
+		return token.Position{}
+	}
+	return pkg.FileSet.Position(pos)
+}
+
+// ObjectOf returns the object denoted by the identifier: the defined
+// object for declarations, and the used object otherwise.
+func (pkg *Package) ObjectOf(ident *ast.Ident) (types.Object, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	obj := pkg.Info.ObjectOf(ident)
+	if obj == nil {
+		return nil, fmt.Errorf("no object for identifier '%s' at %s", ident.Name, pkg.position(ident.Pos()))
+	}
+	return obj, nil
+}
+
+// ImplicitObjectOf returns the object implicitly declared by the node,
+// like a package name of an unnamed import, a variable of a type switch
+// clause or an anonymous parameter.
+func (pkg *Package) ImplicitObjectOf(node ast.Node) (types.Object, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	obj, ok := pkg.Info.Implicits[node]
+	if !ok {
+		return nil, fmt.Errorf("no implicit object for node %T at %s", node, pkg.position(node.Pos()))
+	}
+	return obj, nil
+}
+
+// SelectionOf returns the selection of a field or a method by the selector
+// expression (qualified identifiers like "fmt.Println" are not
+// selections, use ObjectOf on the Sel instead).
+func (pkg *Package) SelectionOf(expr *ast.SelectorExpr) (*types.Selection, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	selection, ok := pkg.Info.Selections[expr]
+	if !ok {
+		return nil, fmt.Errorf("no selection for '%s' at %s", expr.Sel.Name, pkg.position(expr.Pos()))
+	}
+	return selection, nil
+}
+
+// ScopeOf returns the scope declared by the node (like a file, a function
+// type, a block or a clause).
+func (pkg *Package) ScopeOf(node ast.Node) (*types.Scope, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	scope, ok := pkg.Info.Scopes[node]
+	if !ok {
+		return nil, fmt.Errorf("no scope for node %T at %s", node, pkg.position(node.Pos()))
+	}
+	return scope, nil
+}
+
+// InnermostScope returns the innermost scope which contains the position.
+func (pkg *Package) InnermostScope(pos token.Pos) (*types.Scope, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	file := pkg.Files.findByPos(pos)
+	if file == nil {
+		return nil, fmt.Errorf("position %s is not in package '%s'", pkg.position(pos), pkg.Name)
+	}
+	fileScope, ok := pkg.Info.Scopes[file.Ast]
+	if !ok {
+		return nil, fmt.Errorf("no scope for file '%s'", file.Path)
 	}
-	return pkg.Info.Types[expr], nil
+	return fileScope.Innermost(pos), nil
+}
+
+// GoVersion returns the Go version used for the file (defined by
+// the "//go:build" constraint or by the configuration of the type checker).
+func (pkg *Package) GoVersion(file *File) (string, error) {
+	if err := pkg.checkInfo(); err != nil {
+		return "", err
+	}
+	version, ok := pkg.Info.FileVersions[file.Ast]
+	if !ok {
+		return "", fmt.Errorf("no Go version for file '%s'", file.Path)
+	}
+	return version, nil
 }
 
 // Importer is an interface of an external imported which could be used
@@ -81,3 +189,36 @@ func (pkg Package) Funcs() Funcs {
 	}
 	return result
 }
+
+// AllFuncs returns all the functions of the package: both methods and
+// functions without a receiver.
+func (pkg Package) AllFuncs() Funcs {
+	var result Funcs
+	for _, file := range pkg.Files {
+		result = append(result, file.AllFuncs()...)
+	}
+	return result
+}
+
+// AstTypeSpecs returns all the type definitions of the package.
+func (pkg Package) AstTypeSpecs() AstTypeSpecs {
+	var result AstTypeSpecs
+	for _, file := range pkg.Files {
+		result = append(result, file.AstTypeSpecs()...)
+	}
+	return result
+}
+
+// Structs returns all the structures of the package. Structures of test
+// files and of files excluded by build tags are not included, since they
+// have no type information.
+func (pkg *Package) Structs() Structs {
+	var result Structs
+	for _, file := range pkg.Files {
+		if file.Package != pkg {
+			continue
+		}
+		result = append(result, file.Structs()...)
+	}
+	return result
+}
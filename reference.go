@@ -0,0 +1,334 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// ReferenceKind defines how a referenced object is used.
+type ReferenceKind uint
+
+const (
+	// ReferenceKindUndefined is an undefined kind of reference.
+	ReferenceKindUndefined = ReferenceKind(iota)
+
+	// ReferenceKindRead is a read of a variable or a field, or a use of
+	// a function as a value.
+	ReferenceKindRead
+
+	// ReferenceKindWrite is an assignment to a variable or a field (including
+	// "++", "--" and taking the address with "&").
+	ReferenceKindWrite
+
+	// ReferenceKindCall is a call of a function or a method.
+	ReferenceKindCall
+
+	// ReferenceKindCompositeLitKey is a use of a field as a key in
+	// a composite literal (like "Name" in "T{Name: name}").
+	ReferenceKindCompositeLitKey
+
+	// ReferenceKindCompositeLit is a construction of a value of a type with
+	// a composite literal (like "T{...}" or "&T{...}").
+	ReferenceKindCompositeLit
+
+	// ReferenceKindConversion is a conversion to a type (like "T(v)").
+	ReferenceKindConversion
+
+	// ReferenceKindEmbedding is an embedding of a type into a structure
+	// or an interface.
+	ReferenceKindEmbedding
+
+	// ReferenceKindType is any other use of a type (like in declarations
+	// of variables, fields and signatures).
+	ReferenceKindType
+)
+
+// String just implements fmt.Stringer
+func (kind ReferenceKind) String() string {
+	switch kind {
+	case ReferenceKindUndefined:
+		return "undefined"
+	case ReferenceKindRead:
+		return "read"
+	case ReferenceKindWrite:
+		return "write"
+	case ReferenceKindCall:
+		return "call"
+	case ReferenceKindCompositeLitKey:
+		return "composite_lit_key"
+	case ReferenceKindCompositeLit:
+		return "composite_lit"
+	case ReferenceKindConversion:
+		return "conversion"
+	case ReferenceKindEmbedding:
+		return "embedding"
+	case ReferenceKindType:
+		return "type"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// Reference is one usage of an object.
+type Reference struct {
+	Kind ReferenceKind
+
+	// Ident is the identifier referencing the object.
+	Ident *ast.Ident
+
+	Package  *Package
+	File     *File
+	Position token.Position
+}
+
+// References is a set of Reference-s.
+type References []*Reference
+
+// String just implements fmt.Stringer
+func (ref Reference) String() string {
+	return fmt.Sprintf("%s: %s %s", ref.Position, ref.Kind, ref.Ident.Name)
+}
+
+// FilterByKind returns only references of the specified kinds.
+func (refs References) FilterByKind(kinds ...ReferenceKind) References {
+	var result References
+	for _, ref := range refs {
+		for _, kind := range kinds {
+			if ref.Kind == kind {
+				result = append(result, ref)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// objectKey returns a key which identifies the declaration of the object
+// independently of the type-checking pass, which produced the object.
+func (pkg *Package) objectKey(obj types.Object) string {
+	if !obj.Pos().IsValid() {
+		// predeclared objects are shared
+		return fmt.Sprintf("%p", obj)
+	}
+	position := pkg.position(obj.Pos())
+	return fmt.Sprintf("%s:%d", position.Filename, position.Offset)
+}
+
+// FindReferences returns all the usages of the object in the packages
+// (declarations are not included). The object could be taken from any
+// of the packages or from a package loaded independently.
+//
+// The packages should be opened without "onlyFiles". Packages without
+// FileSet (like packages returned by an external Importer) are skipped.
+func (pkgs Packages) FindReferences(obj types.Object, objPkg *Package) (References, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("object is nil")
+	}
+	key := objPkg.objectKey(obj)
+
+	var result References
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.FileSet == nil {
+			continue
+		}
+		if err := pkg.checkInfo(); err != nil {
+			return nil, err
+		}
+		for _, file := range pkg.Files {
+			if file.Package != pkg {
+				continue
+			}
+			result = append(result, pkg.findReferencesInFile(file, key)...)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].Position, result[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return result, nil
+}
+
+func (pkg *Package) findReferencesInFile(file *File, key string) References {
+	var (
+		result References
+		stack  []ast.Node
+	)
+	ast.Inspect(file.Ast, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if ident, ok := node.(*ast.Ident); ok {
+			if obj := pkg.Info.Uses[ident]; obj != nil && pkg.objectKey(obj) == key {
+				result = append(result, &Reference{
+					Kind:     classifyReference(ident, obj, stack),
+					Ident:    ident,
+					Package:  pkg,
+					File:     file,
+					Position: pkg.position(ident.Pos()),
+				})
+			}
+		}
+		stack = append(stack, node)
+		return true
+	})
+	return result
+}
+
+// classifyReference defines the kind of the reference by the identifier
+// and its parents (the last one is the direct parent).
+func classifyReference(ident *ast.Ident, obj types.Object, parents []ast.Node) ReferenceKind {
+	parent := func(idx int) ast.Node {
+		if idx >= len(parents) {
+			return nil
+		}
+		return parents[len(parents)-1-idx]
+	}
+
+	// expr is the whole expression denoting the object (like "x.Field"
+	// or "pkg.Type"), and exprIdx is the index of its parent.
+	var expr ast.Expr = ident
+	exprIdx := 0
+	if selectorExpr, ok := parent(0).(*ast.SelectorExpr); ok && selectorExpr.Sel == ident {
+		expr = selectorExpr
+		exprIdx = 1
+	}
+	for {
+		parenExpr, ok := parent(exprIdx).(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = parenExpr
+		exprIdx++
+	}
+
+	if keyValueExpr, ok := parent(exprIdx).(*ast.KeyValueExpr); ok && keyValueExpr.Key == expr {
+		if _, ok := parent(exprIdx + 1).(*ast.CompositeLit); ok {
+			if _, ok := obj.(*types.Var); ok {
+				return ReferenceKindCompositeLitKey
+			}
+		}
+	}
+
+	switch obj.(type) {
+	case *types.TypeName:
+		return classifyTypeReference(expr, parents[:len(parents)-exprIdx])
+	case *types.Func, *types.Builtin:
+		if callExpr, ok := parent(exprIdx).(*ast.CallExpr); ok && callExpr.Fun == expr {
+			return ReferenceKindCall
+		}
+		return ReferenceKindRead
+	case *types.Var:
+		switch stmt := parent(exprIdx).(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range stmt.Lhs {
+				if lhs == expr {
+					return ReferenceKindWrite
+				}
+			}
+		case *ast.IncDecStmt:
+			return ReferenceKindWrite
+		case *ast.RangeStmt:
+			if stmt.Tok == token.ASSIGN && (stmt.Key == expr || stmt.Value == expr) {
+				return ReferenceKindWrite
+			}
+		case *ast.UnaryExpr:
+			if stmt.Op == token.AND {
+				return ReferenceKindWrite
+			}
+		case *ast.CallExpr:
+			if stmt.Fun == expr {
+				return ReferenceKindCall
+			}
+		}
+		return ReferenceKindRead
+	default:
+		return ReferenceKindRead
+	}
+}
+
+func classifyTypeReference(expr ast.Expr, parents []ast.Node) ReferenceKind {
+	var node ast.Node = expr
+	for idx := len(parents) - 1; idx >= 0; idx-- {
+		switch parent := parents[idx].(type) {
+		case *ast.CallExpr:
+			if parent.Fun == node {
+				return ReferenceKindConversion
+			}
+			return ReferenceKindType
+		case *ast.CompositeLit:
+			if parent.Type == node {
+				return ReferenceKindCompositeLit
+			}
+			return ReferenceKindType
+		case *ast.Field:
+			if parent.Type != node {
+				return ReferenceKindType
+			}
+			if len(parent.Names) == 0 && idx >= 2 {
+				// parents[idx-1] is *ast.FieldList
+				switch parents[idx-2].(type) {
+				case *ast.StructType, *ast.InterfaceType:
+					return ReferenceKindEmbedding
+				}
+			}
+			return ReferenceKindType
+		case *ast.StarExpr, *ast.ParenExpr, *ast.IndexExpr, *ast.IndexListExpr:
+			// for example: "*T" in an embedded field, or "(T)" in a conversion
+			node = parent
+		default:
+			return ReferenceKindType
+		}
+	}
+	return ReferenceKindType
+}
+
+// References returns all the usages of the type in the packages.
+func (astTypeSpec AstTypeSpec) References(pkgs Packages) (References, error) {
+	obj := astTypeSpec.Object()
+	if obj == nil {
+		return nil, fmt.Errorf("no type information for type '%s'", astTypeSpec.Name())
+	}
+	return pkgs.FindReferences(obj, astTypeSpec.File.Package)
+}
+
+// References returns all the usages of the function (or method) in
+// the packages.
+func (fn Func) References(pkgs Packages) (References, error) {
+	pkg := fn.File.Package
+	obj, err := pkg.ObjectOf(fn.FuncDecl.Name)
+	if err != nil {
+		return nil, err
+	}
+	return pkgs.FindReferences(obj, pkg)
+}
+
+// References returns all the usages of the field in the packages. If
+// the field declares multiple names (like "A, B int"), then only
+// the first one is considered.
+func (field Field) References(pkgs Packages) (References, error) {
+	pkg := field.Struct.File.Package
+	var ident *ast.Ident
+	if len(field.Names) > 0 {
+		ident = field.Names[0]
+	} else {
+		ident = embeddedFieldIdent(field.Type)
+	}
+	if ident == nil {
+		return nil, fmt.Errorf("unable to find the identifier of field '%s'", field.Name())
+	}
+	if err := pkg.checkInfo(); err != nil {
+		return nil, err
+	}
+	obj := pkg.Info.Defs[ident]
+	if obj == nil {
+		return nil, fmt.Errorf("no object for field '%s'", field.Name())
+	}
+	return pkgs.FindReferences(obj, pkg)
+}
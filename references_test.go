@@ -0,0 +1,77 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func referenceKinds(refs gosrc.References) []gosrc.ReferenceKind {
+	var result []gosrc.ReferenceKind
+	for _, ref := range refs {
+		result = append(result, ref.Kind)
+	}
+	return result
+}
+
+func TestFindReferences(t *testing.T) {
+	pkg := openTestPackage(t, "references")
+	pkgs := gosrc.Packages{pkg}
+
+	user := findStruct(t, pkg, "User")
+	refs, err := user.References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{
+		gosrc.ReferenceKindEmbedding,
+		gosrc.ReferenceKindType,
+		gosrc.ReferenceKindCompositeLit,
+		gosrc.ReferenceKindType,
+		gosrc.ReferenceKindCompositeLit,
+	}, referenceKinds(refs))
+
+	fields, err := user.Fields()
+	require.NoError(t, err)
+
+	refs, err = fields[1].References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{
+		gosrc.ReferenceKindCompositeLitKey,
+		gosrc.ReferenceKindWrite,
+		gosrc.ReferenceKindRead,
+	}, referenceKinds(refs))
+
+	refs, err = fields[2].References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{
+		gosrc.ReferenceKindWrite,
+		gosrc.ReferenceKindWrite,
+		gosrc.ReferenceKindRead,
+	}, referenceKinds(refs))
+
+	newUser := pkg.AllFuncs().FindByName("NewUser")[0]
+	refs, err = newUser.References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{
+		gosrc.ReferenceKindCall,
+		gosrc.ReferenceKindRead,
+	}, referenceKinds(refs))
+
+	rename := user.MethodByName("Rename")
+	refs, err = rename.References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{gosrc.ReferenceKindCall}, referenceKinds(refs))
+
+	id := pkg.AstTypeSpecs().FindByName("ID")
+	refs, err = id.References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{
+		gosrc.ReferenceKindType,
+		gosrc.ReferenceKindConversion,
+	}, referenceKinds(refs))
+
+	otherPkg := openTestPackage(t, "embedding/other")
+	refs, err = findStruct(t, otherPkg, "Meta").References(pkgs)
+	require.NoError(t, err)
+	require.Equal(t, []gosrc.ReferenceKind{gosrc.ReferenceKindEmbedding}, referenceKinds(refs))
+}
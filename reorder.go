@@ -0,0 +1,240 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// ReorderFieldsOptimally reorders the fields of the structure in the source
+// code to the order returned by StructLayout.OptimalOrder for the GOARCH.
+// Doc comments and line comments are moved together with the fields.
+//
+// The source code file is not changed, see FileEdit.
+func (_struct *Struct) ReorderFieldsOptimally(goarch string) (*FileEdit, error) {
+	layout, err := _struct.Layout(goarch)
+	if err != nil {
+		return nil, err
+	}
+	typesFieldIndexes := map[*FieldLayout]int{}
+	for idx, field := range layout.Fields {
+		typesFieldIndexes[field] = idx
+	}
+	var order []int
+	for _, field := range layout.OptimalOrder() {
+		order = append(order, typesFieldIndexes[field])
+	}
+	return _struct.reorderFields(order)
+}
+
+// ReorderFields reorders the fields of the structure in the source code
+// to the specified order of names (all the fields should be listed).
+// Doc comments and line comments are moved together with the fields.
+// Fields declared together (like "A, B int") should stay together
+// in the same order.
+//
+// The source code file is not changed, see FileEdit.
+func (_struct *Struct) ReorderFields(names []string) (*FileEdit, error) {
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, err
+	}
+	typesFieldIndexes := map[string]int{}
+	typesFieldIdx := 0
+	for _, field := range fields {
+		for _, name := range fieldNames(field) {
+			if _, ok := typesFieldIndexes[name]; ok || name == "_" {
+				return nil, fmt.Errorf("cannot reorder %s by names: field name '%s' is ambiguous", _struct, name)
+			}
+			typesFieldIndexes[name] = typesFieldIdx
+			typesFieldIdx++
+		}
+	}
+	var order []int
+	for _, name := range names {
+		idx, ok := typesFieldIndexes[name]
+		if !ok {
+			return nil, fmt.Errorf("there is no field '%s' in %s", name, _struct)
+		}
+		order = append(order, idx)
+	}
+	return _struct.reorderFields(order)
+}
+
+// fieldNames returns the names of the types.Struct fields declared
+// by the field.
+func fieldNames(field *Field) []string {
+	if field.IsEmbedded() {
+		return []string{field.Name()}
+	}
+	var result []string
+	for _, ident := range field.Names {
+		result = append(result, ident.Name)
+	}
+	return result
+}
+
+// reorderFields reorders the fields by the order of types.Struct field
+// indexes.
+func (_struct *Struct) reorderFields(order []int) (*FileEdit, error) {
+	file := _struct.File
+	structType := _struct.StructType()
+	if structType == nil {
+		return nil, fmt.Errorf("no struct type in %s", _struct)
+	}
+	if file.FileSet == nil || file.src == nil {
+		return nil, fmt.Errorf("the source code of file '%s' is not available", file.Path)
+	}
+	astFields := structType.Fields.List
+
+	fieldOrder, err := astFieldOrder(astFields, order)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reorder fields of %s: %w", _struct, err)
+	}
+	edit := &FileEdit{
+		File:     file,
+		Original: file.src,
+		Modified: file.src,
+	}
+	isSorted := true
+	for idx, fieldIdx := range fieldOrder {
+		if idx != fieldIdx {
+			isSorted = false
+		}
+	}
+	if isSorted {
+		return edit, nil
+	}
+
+	chunks, err := _struct.fieldChunks()
+	if err != nil {
+		return nil, fmt.Errorf("unable to reorder fields of %s: %w", _struct, err)
+	}
+
+	var fieldsSource bytes.Buffer
+	for idx, fieldIdx := range fieldOrder {
+		chunk := chunks[fieldIdx]
+		fieldsSource.Write(file.src[chunk.start:chunk.end])
+		if idx+1 < len(chunks) {
+			// whatever is between the fields (empty lines, free-floating
+			// comments) is kept in place
+			fieldsSource.Write(file.src[chunks[idx].end:chunks[idx+1].start])
+		}
+	}
+
+	// the alignment of types, tags and comments of neighboring lines
+	// might have been changed, so the declaration is reformatted
+	modified, err := file.applyTextEdits([]textEdit{{
+		start: chunks[0].start,
+		end:   chunks[len(chunks)-1].end,
+		text:  fieldsSource.String(),
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("unable to reorder fields of %s: %w", _struct, err)
+	}
+	edit.Modified = modified
+	return edit, nil
+}
+
+// astFieldOrder converts the order of types.Struct field indexes to
+// the order of AST fields.
+func astFieldOrder(astFields []*ast.Field, order []int) ([]int, error) {
+	var owners []int
+	var firstIdx []int
+	for astIdx, field := range astFields {
+		firstIdx = append(firstIdx, len(owners))
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for idx := 0; idx < count; idx++ {
+			owners = append(owners, astIdx)
+		}
+	}
+	if len(order) != len(owners) {
+		return nil, fmt.Errorf("expected %d fields, but got %d", len(owners), len(order))
+	}
+	seen := make([]bool, len(owners))
+	var result []int
+	for idx := 0; idx < len(order); {
+		typesFieldIdx := order[idx]
+		if typesFieldIdx < 0 || typesFieldIdx >= len(owners) || seen[typesFieldIdx] {
+			return nil, fmt.Errorf("invalid or repeated field index %d", typesFieldIdx)
+		}
+		astIdx := owners[typesFieldIdx]
+		field := astFields[astIdx]
+		for nameIdx := range field.Names {
+			expected := firstIdx[astIdx] + nameIdx
+			if idx+nameIdx >= len(order) || order[idx+nameIdx] != expected {
+				return nil, fmt.Errorf("fields '%s' are declared together and cannot be split", astFieldName(field))
+			}
+		}
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for nameIdx := 0; nameIdx < count; nameIdx++ {
+			seen[order[idx+nameIdx]] = true
+		}
+		result = append(result, astIdx)
+		idx += count
+	}
+	return result, nil
+}
+
+func astFieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return embeddedFieldName(field.Type)
+	}
+	var names []string
+	for _, ident := range field.Names {
+		names = append(names, ident.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// fieldChunks returns the ranges of the whole lines of the fields of
+// the structure (see fieldLinesChunk). It returns an error if a field
+// shares a line with another field or with a brace of the structure.
+func (_struct *Struct) fieldChunks() ([]sourceChunk, error) {
+	file := _struct.File
+	structType := _struct.StructType()
+	tokenFile := file.FileSet.File(structType.Pos())
+	chunks := make([]sourceChunk, 0, len(structType.Fields.List))
+	prevEnd := tokenFile.Offset(structType.Fields.Opening)
+	for _, field := range structType.Fields.List {
+		chunk := fieldLinesChunk(tokenFile, field)
+		if chunk.start <= prevEnd {
+			return nil, fmt.Errorf("field '%s' at %s does not start a new line",
+				astFieldName(field), tokenFile.Position(field.Pos()))
+		}
+		chunks = append(chunks, chunk)
+		prevEnd = chunk.end - 1
+	}
+	if closing := tokenFile.Offset(structType.Fields.Closing); closing < prevEnd {
+		return nil, fmt.Errorf("the closing brace is on the line of the last field")
+	}
+	return chunks, nil
+}
+
+// fieldLinesChunk returns the range of the whole lines of the field,
+// including its doc comment and line comment.
+func fieldLinesChunk(tokenFile *token.File, field *ast.Field) sourceChunk {
+	start, end := field.Pos(), field.End()
+	if field.Doc != nil {
+		start = field.Doc.Pos()
+	}
+	if field.Comment != nil {
+		end = field.Comment.End()
+	}
+	startLine, endLine := tokenFile.Line(start), tokenFile.Line(end)
+	chunk := sourceChunk{start: tokenFile.Offset(tokenFile.LineStart(startLine))}
+	if endLine < tokenFile.LineCount() {
+		chunk.end = tokenFile.Offset(tokenFile.LineStart(endLine + 1))
+	} else {
+		chunk.end = tokenFile.Size()
+	}
+	return chunk
+}
@@ -0,0 +1,187 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// position returns the position in the file (or an invalid position
+// if it is unknown).
+func (file *File) position(pos token.Pos) token.Position {
+	if file == nil || file.FileSet == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	return file.FileSet.Position(pos)
+}
+
+// Source returns the content of the file at the moment of parsing.
+func (file File) Source() []byte {
+	return file.src
+}
+
+// source returns the original text between the positions.
+func (file *File) source(start, end token.Pos) (string, error) {
+	if file.FileSet == nil || file.src == nil {
+		return "", fmt.Errorf("the source code of file '%s' is not available", file.Path)
+	}
+	tokenFile := file.FileSet.File(start)
+	if tokenFile == nil || !start.IsValid() || !end.IsValid() {
+		return "", fmt.Errorf("invalid positions %d-%d in file '%s'", start, end, file.Path)
+	}
+	startOffset, endOffset := tokenFile.Offset(start), tokenFile.Offset(end)
+	if startOffset > endOffset || endOffset > len(file.src) {
+		return "", fmt.Errorf("invalid offsets %d-%d in file '%s'", startOffset, endOffset, file.Path)
+	}
+	return string(file.src[startOffset:endOffset]), nil
+}
+
+// sourceWithComments returns the original text of the node together with
+// its doc comment and line comment (any of the comments could be nil).
+func (file *File) sourceWithComments(doc *ast.CommentGroup, node ast.Node, lineComment *ast.CommentGroup) (string, error) {
+	start, end := node.Pos(), node.End()
+	if doc != nil && doc.Pos() < start {
+		start = doc.Pos()
+	}
+	if lineComment != nil && lineComment.End() > end {
+		end = lineComment.End()
+	}
+	return file.source(start, end)
+}
+
+// declNode returns the node of the type definition: the whole declaration
+// for a non-grouped declaration (to include the "type" keyword), or
+// the specification otherwise. It also returns the doc comment of it.
+func (astTypeSpec AstTypeSpec) declNode() (ast.Node, *ast.CommentGroup) {
+	if astTypeSpec.IsAnonymous() {
+		return astTypeSpec.TypeSpec.Type, nil
+	}
+	return specDeclNode(astTypeSpec.File, astTypeSpec.TypeSpec, astTypeSpec.TypeSpec.Doc)
+}
+
+func specDeclNode(file *File, spec ast.Spec, doc *ast.CommentGroup) (ast.Node, *ast.CommentGroup) {
+	genDecl := file.genDeclOf(spec)
+	if genDecl == nil {
+		return spec, doc
+	}
+	if !genDecl.Lparen.IsValid() {
+		return genDecl, genDecl.Doc
+	}
+	if doc != nil && doc.Pos() < genDecl.Lparen {
+		// the doc comment of the whole group
+		doc = nil
+	}
+	return spec, doc
+}
+
+// Position returns the position of the beginning of the type definition:
+// of the "type" keyword, or of the name for a type inside of a grouped
+// declaration "type ( ... )".
+func (astTypeSpec AstTypeSpec) Position() token.Position {
+	node, _ := astTypeSpec.declNode()
+	return astTypeSpec.File.position(node.Pos())
+}
+
+// EndPosition returns the position right after the type definition.
+func (astTypeSpec AstTypeSpec) EndPosition() token.Position {
+	node, _ := astTypeSpec.declNode()
+	return astTypeSpec.File.position(node.End())
+}
+
+// Source returns the original text of the type definition, including
+// its doc comment and line comment.
+func (astTypeSpec AstTypeSpec) Source() (string, error) {
+	node, doc := astTypeSpec.declNode()
+	if astTypeSpec.IsAnonymous() {
+		return astTypeSpec.File.source(node.Pos(), node.End())
+	}
+	return astTypeSpec.File.sourceWithComments(doc, node, astTypeSpec.TypeSpec.Comment)
+}
+
+// Position returns the position of the beginning of the field (of its
+// name, or of its type for embedded fields).
+func (field Field) Position() token.Position {
+	return field.Struct.File.position(field.Field.Pos())
+}
+
+// EndPosition returns the position right after the field (including the tag).
+func (field Field) EndPosition() token.Position {
+	return field.Struct.File.position(field.Field.End())
+}
+
+// Source returns the original text of the field, including its doc
+// comment and line comment.
+func (field Field) Source() (string, error) {
+	return field.Struct.File.sourceWithComments(field.Field.Doc, &field.Field, field.Field.Comment)
+}
+
+// Position returns the position of the "func" keyword of the function.
+func (fn Func) Position() token.Position {
+	return fn.File.position(fn.FuncDecl.Pos())
+}
+
+// EndPosition returns the position right after the function.
+func (fn Func) EndPosition() token.Position {
+	return fn.File.position(fn.FuncDecl.End())
+}
+
+// Source returns the original text of the function, including its doc
+// comment.
+func (fn Func) Source() (string, error) {
+	return fn.File.sourceWithComments(fn.FuncDecl.Doc, fn.FuncDecl, nil)
+}
+
+// Position returns the position of the name of the method.
+func (method InterfaceMethod) Position() token.Position {
+	return method.Interface.File.position(method.Field.Pos())
+}
+
+// EndPosition returns the position right after the method.
+func (method InterfaceMethod) EndPosition() token.Position {
+	return method.Interface.File.position(method.Field.End())
+}
+
+// Source returns the original text of the method, including its doc
+// comment and line comment.
+func (method InterfaceMethod) Source() (string, error) {
+	return method.Interface.File.sourceWithComments(method.Field.Doc, method.Field, method.Field.Comment)
+}
+
+// Position returns the position of the beginning of the constants: of
+// the "const" keyword, or of the first name for constants inside of
+// a grouped declaration "const ( ... )".
+func (_const Const) Position() token.Position {
+	node, _ := specDeclNode(_const.File, _const.ValueSpec, _const.ValueSpec.Doc)
+	return _const.File.position(node.Pos())
+}
+
+// EndPosition returns the position right after the constants.
+func (_const Const) EndPosition() token.Position {
+	node, _ := specDeclNode(_const.File, _const.ValueSpec, _const.ValueSpec.Doc)
+	return _const.File.position(node.End())
+}
+
+// Source returns the original text of the constants, including the doc
+// comment and the line comment.
+func (_const Const) Source() (string, error) {
+	node, doc := specDeclNode(_const.File, _const.ValueSpec, _const.ValueSpec.Doc)
+	return _const.File.sourceWithComments(doc, node, _const.ValueSpec.Comment)
+}
+
+// Position returns the position of the identifier of the generic type
+// or function at the place where it is instantiated (or an invalid
+// position for instantiations found by Instantiations.Transitive).
+func (instantiation Instantiation) Position() token.Position {
+	if instantiation.Ident == nil {
+		return token.Position{}
+	}
+	return instantiation.File.position(instantiation.Ident.Pos())
+}
+
+// EndPosition returns the position right after the identifier (see Position).
+func (instantiation Instantiation) EndPosition() token.Position {
+	if instantiation.Ident == nil {
+		return token.Position{}
+	}
+	return instantiation.File.position(instantiation.Ident.End())
+}
@@ -0,0 +1,436 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// SourceEditor collects modifications of a source code file made through
+// the model (structures, fields, functions). The modifications are
+// applied to the original text of the file, so comments and formatting of
+// the rest of the file are preserved.
+//
+// All the objects passed to the methods should belong to the file and
+// positions are those of the moment of parsing, so multiple
+// modifications of the same code could conflict (see Apply).
+type SourceEditor struct {
+	File *File
+
+	edits   []textEdit
+	imports []editorImport
+}
+
+// editorImport is an import added by a SourceEditor.
+type editorImport struct {
+	path string
+	name string
+}
+
+// NewSourceEditor returns a new editor of the file.
+func NewSourceEditor(file *File) *SourceEditor {
+	return &SourceEditor{File: file}
+}
+
+func (editor *SourceEditor) offset(pos token.Pos) int {
+	return editor.File.FileSet.File(pos).Offset(pos)
+}
+
+func (editor *SourceEditor) replace(start, end int, text string) {
+	editor.edits = append(editor.edits, textEdit{start: start, end: end, text: text})
+}
+
+func (editor *SourceEditor) checkSource() error {
+	if editor.File.FileSet == nil || editor.File.src == nil {
+		return fmt.Errorf("the source code of file '%s' is not available", editor.File.Path)
+	}
+	return nil
+}
+
+func (editor *SourceEditor) checkFile(file *File, name string) error {
+	if err := editor.checkSource(); err != nil {
+		return err
+	}
+	if file != editor.File {
+		return fmt.Errorf("%s is not declared in file '%s'", name, editor.File.Path)
+	}
+	return nil
+}
+
+// lineStart returns the offset of the beginning of the line and
+// the indentation of the line, if only spaces precede the offset
+// in the line. Otherwise it returns -1.
+func (editor *SourceEditor) lineStart(offset int) (int, string) {
+	start := bytes.LastIndexByte(editor.File.src[:offset], '\n') + 1
+	indent := editor.File.src[start:offset]
+	if len(bytes.TrimLeft(indent, " \t")) > 0 {
+		return -1, ""
+	}
+	return start, string(indent)
+}
+
+// insertBeforeClosing inserts the lines before the closing brace or
+// parenthesis.
+func (editor *SourceEditor) insertBeforeClosing(closing token.Pos, lines []string) {
+	offset := editor.offset(closing)
+	if start, indent := editor.lineStart(offset); start >= 0 {
+		editor.replace(start, start, indentLines(lines, indent+"\t"))
+		return
+	}
+	editor.replace(offset, offset, "\n"+indentLines(lines, "\t"))
+}
+
+func indentLines(lines []string, indent string) string {
+	var result strings.Builder
+	for _, line := range lines {
+		result.WriteString(indent + line + "\n")
+	}
+	return result.String()
+}
+
+// commentLines returns the lines of a doc comment with the text
+// (or nil for an empty text).
+func commentLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	var result []string
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			result = append(result, "//")
+			continue
+		}
+		result = append(result, "// "+line)
+	}
+	return result
+}
+
+// AddField appends the field to the end of the structure. The declaration
+// is the Go source of the field, like "Name string `json:\"name\"`", and
+// the doc is the text of its doc comment (could be empty).
+func (editor *SourceEditor) AddField(_struct *Struct, declaration, doc string) error {
+	if err := editor.checkFile(_struct.File, _struct.String()); err != nil {
+		return err
+	}
+	structType := _struct.StructType()
+	if structType == nil {
+		return fmt.Errorf("no struct type in %s", _struct)
+	}
+	expr, err := parser.ParseExpr("struct{\n" + declaration + "\n}")
+	if err != nil || len(expr.(*ast.StructType).Fields.List) != 1 {
+		return fmt.Errorf("invalid field declaration '%s': expected exactly one field", declaration)
+	}
+	editor.insertBeforeClosing(structType.Fields.Closing, append(commentLines(doc), strings.TrimSpace(declaration)))
+	return nil
+}
+
+// RemoveField removes the field (all the names for fields like
+// "A, B int") together with its doc comment and line comment.
+func (editor *SourceEditor) RemoveField(field *Field) error {
+	if err := editor.checkFile(field.Struct.File, field.Struct.String()); err != nil {
+		return err
+	}
+	chunks, err := field.Struct.fieldChunks()
+	if err != nil {
+		return fmt.Errorf("unable to remove field '%s' of %s: %w", field.Name(), field.Struct, err)
+	}
+	chunk := chunks[field.Index]
+	editor.replace(chunk.start, chunk.end, "")
+	return nil
+}
+
+// SetTag sets the value of the key of the struct field tag (like
+// SetTag(field, "json", "name,omitempty")). Other keys are kept in
+// the same order.
+func (editor *SourceEditor) SetTag(field *Field, key, value string) error {
+	return editor.editTags(field, func(tags Tags) Tags {
+		parts := strings.Split(value, ",")
+		newTag := &Tag{Key: key, Name: parts[0], Options: parts[1:]}
+		for idx, tag := range tags {
+			if tag.Key == key {
+				tags[idx] = newTag
+				return tags
+			}
+		}
+		return append(tags, newTag)
+	})
+}
+
+// RemoveTag removes the key from the struct field tag (the whole tag is
+// removed if there are no keys left).
+func (editor *SourceEditor) RemoveTag(field *Field, key string) error {
+	return editor.editTags(field, func(tags Tags) Tags {
+		var result Tags
+		for _, tag := range tags {
+			if tag.Key != key {
+				result = append(result, tag)
+			}
+		}
+		return result
+	})
+}
+
+func (editor *SourceEditor) editTags(field *Field, modify func(Tags) Tags) error {
+	if err := editor.checkFile(field.Struct.File, field.Struct.String()); err != nil {
+		return err
+	}
+	tags, err := field.Tags()
+	if err != nil {
+		return err
+	}
+	tags = modify(append(Tags{}, tags...))
+
+	literal := ""
+	if len(tags) > 0 {
+		literal = tags.String()
+		if strings.Contains(literal, "`") {
+			literal = strconv.Quote(literal)
+		} else {
+			literal = "`" + literal + "`"
+		}
+	}
+	if field.Field.Tag == nil {
+		if literal != "" {
+			offset := editor.offset(field.Field.Type.End())
+			editor.replace(offset, offset, " "+literal)
+		}
+		return nil
+	}
+	start, end := editor.offset(field.Field.Type.End()), editor.offset(field.Field.Tag.End())
+	if literal != "" {
+		literal = " " + literal
+	}
+	editor.replace(start, end, literal)
+	return nil
+}
+
+// AddMethod adds the method (the Go source of the function declaration
+// with a receiver) after the last method of the type declared in the file,
+// or after the type declaration if there are no such methods.
+func (editor *SourceEditor) AddMethod(astTypeSpec *AstTypeSpec, source string) error {
+	if err := editor.checkFile(astTypeSpec.File, astTypeSpec.Name()); err != nil {
+		return err
+	}
+	if astTypeSpec.IsAnonymous() {
+		return fmt.Errorf("cannot add a method to an anonymous type")
+	}
+	parsed, err := parser.ParseFile(token.NewFileSet(), "", "package p\n\n"+source, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("invalid method declaration: %w", err)
+	}
+	if len(parsed.Decls) != 1 {
+		return fmt.Errorf("expected exactly one method declaration, got %d declarations", len(parsed.Decls))
+	}
+	funcDecl, ok := parsed.Decls[0].(*ast.FuncDecl)
+	if !ok || funcDecl.Recv == nil {
+		return fmt.Errorf("expected a method declaration, got %T", parsed.Decls[0])
+	}
+	if recvName := embeddedFieldName(funcDecl.Recv.List[0].Type); recvName != astTypeSpec.Name() {
+		return fmt.Errorf("method '%s' has receiver of type '%s', expected '%s'", funcDecl.Name.Name, recvName, astTypeSpec.Name())
+	}
+
+	end := astTypeSpec.TypeSpec.End()
+	if genDecl := editor.File.genDeclOf(astTypeSpec.TypeSpec); genDecl != nil {
+		end = genDecl.End()
+	}
+	for _, method := range editor.File.Funcs().FindMethodsOf(astTypeSpec.Name()) {
+		if method.FuncDecl.End() > end {
+			end = method.FuncDecl.End()
+		}
+	}
+	offset := editor.offset(end)
+	editor.replace(offset, offset, "\n\n"+strings.TrimSpace(source))
+	return nil
+}
+
+// AddImport adds the import of the package (name could be empty). It does
+// nothing if the package is already imported (in the file or by
+// the editor) with the same name.
+func (editor *SourceEditor) AddImport(pkgPath, name string) error {
+	if err := editor.checkSource(); err != nil {
+		return err
+	}
+	for _, importSpec := range editor.File.Ast.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil || path != pkgPath {
+			continue
+		}
+		existingName := ""
+		if importSpec.Name != nil {
+			existingName = importSpec.Name.Name
+		}
+		if existingName != name {
+			return fmt.Errorf("package '%s' is already imported with name '%s'", pkgPath, existingName)
+		}
+		return nil
+	}
+	for _, _import := range editor.imports {
+		if _import.path != pkgPath {
+			continue
+		}
+		if _import.name != name {
+			return fmt.Errorf("package '%s' is already imported with name '%s'", pkgPath, _import.name)
+		}
+		return nil
+	}
+	editor.imports = append(editor.imports, editorImport{path: pkgPath, name: name})
+	return nil
+}
+
+// importEdit returns the edit adding the imports added by AddImport
+// (all of them into the same import declaration).
+func (editor *SourceEditor) importEdit() textEdit {
+	var specs []string
+	for _, _import := range editor.imports {
+		spec := strconv.Quote(_import.path)
+		if _import.name != "" {
+			spec = _import.name + " " + spec
+		}
+		specs = append(specs, spec)
+	}
+
+	for _, decl := range editor.File.Ast.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		if genDecl.Lparen.IsValid() {
+			offset := editor.offset(genDecl.Rparen)
+			if start, indent := editor.lineStart(offset); start >= 0 {
+				return textEdit{start: start, end: start, text: indentLines(specs, indent+"\t")}
+			}
+			return textEdit{start: offset, end: offset, text: "\n" + indentLines(specs, "\t")}
+		}
+		// convert "import "fmt"" to "import ( ... )"
+		existing := genDecl.Specs[0].(*ast.ImportSpec)
+		end := existing.End()
+		if existing.Comment != nil {
+			end = existing.Comment.End()
+		}
+		start := editor.offset(existing.Pos())
+		existingSource := string(editor.File.src[start:editor.offset(end)])
+		return textEdit{
+			start: start,
+			end:   editor.offset(end),
+			text:  "(\n" + indentLines(append([]string{existingSource}, specs...), "\t") + ")",
+		}
+	}
+
+	offset := editor.offset(editor.File.Ast.Name.End())
+	if len(specs) == 1 {
+		return textEdit{start: offset, end: offset, text: "\n\nimport " + specs[0]}
+	}
+	return textEdit{start: offset, end: offset, text: "\n\nimport (\n" + indentLines(specs, "\t") + ")"}
+}
+
+// SetStructDoc replaces the doc comment of the structure with the text
+// (an empty text removes the doc comment). Directives (like
+// "//go:generate") of the old doc comment are kept.
+func (editor *SourceEditor) SetStructDoc(_struct *Struct, text string) error {
+	if err := editor.checkFile(_struct.File, _struct.String()); err != nil {
+		return err
+	}
+	if _struct.IsAnonymous() {
+		return fmt.Errorf("an anonymous structure cannot have a doc comment")
+	}
+	node, doc := _struct.declNode()
+	return editor.setDoc(doc, node.Pos(), text)
+}
+
+// SetFieldDoc replaces the doc comment of the field, see SetStructDoc.
+func (editor *SourceEditor) SetFieldDoc(field *Field, text string) error {
+	if err := editor.checkFile(field.Struct.File, field.Struct.String()); err != nil {
+		return err
+	}
+	return editor.setDoc(field.Field.Doc, field.Field.Pos(), text)
+}
+
+// SetFuncDoc replaces the doc comment of the function, see SetStructDoc.
+func (editor *SourceEditor) SetFuncDoc(fn *Func, text string) error {
+	if err := editor.checkFile(fn.File, fn.FuncDecl.Name.Name); err != nil {
+		return err
+	}
+	return editor.setDoc(fn.FuncDecl.Doc, fn.FuncDecl.Pos(), text)
+}
+
+func (editor *SourceEditor) setDoc(doc *ast.CommentGroup, declPos token.Pos, text string) error {
+	lines := commentLines(text)
+	if doc != nil {
+		var directives []string
+		for _, comment := range doc.List {
+			if directiveHeadRegexp.MatchString(comment.Text) {
+				directives = append(directives, comment.Text)
+			}
+		}
+		if len(directives) > 0 {
+			if len(lines) > 0 {
+				lines = append(lines, "//")
+			}
+			lines = append(lines, directives...)
+		}
+	}
+
+	if doc == nil {
+		if len(lines) == 0 {
+			return nil
+		}
+		start, indent := editor.lineStart(editor.offset(declPos))
+		if start < 0 {
+			return fmt.Errorf("the declaration at %s does not start a new line", editor.File.position(declPos))
+		}
+		editor.replace(start, start, indentLines(lines, indent))
+		return nil
+	}
+
+	start, end := editor.offset(doc.Pos()), editor.offset(doc.End())
+	lineStart, indent := editor.lineStart(start)
+	if len(lines) == 0 {
+		if lineStart < 0 {
+			editor.replace(start, end, "")
+			return nil
+		}
+		// remove the whole lines
+		editor.replace(lineStart, end+1, "")
+		return nil
+	}
+	editor.replace(start, end, strings.Join(lines, "\n"+indent))
+	return nil
+}
+
+// Apply applies the modifications to the original text of the file and
+// formats the modified declarations (the rest of the file is kept as is).
+// It returns an error if some modifications overlap.
+//
+// The file on the disk is not changed, see FileEdit.Write (and
+// FileEdit.Diff for a dry run).
+func (editor *SourceEditor) Apply() (*FileEdit, error) {
+	file := editor.File
+	if err := editor.checkSource(); err != nil {
+		return nil, err
+	}
+	if len(editor.edits) == 0 && len(editor.imports) == 0 {
+		return &FileEdit{
+			File:     file,
+			Original: file.src,
+			Modified: file.src,
+		}, nil
+	}
+	edits := editor.edits
+	if len(editor.imports) > 0 {
+		edits = append(append([]textEdit{}, edits...), editor.importEdit())
+	}
+	modified, err := file.applyTextEdits(edits)
+	if err != nil {
+		return nil, err
+	}
+	return &FileEdit{
+		File:     file,
+		Original: file.src,
+		Modified: modified,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestSourceEditor(t *testing.T) {
+	pkg := openTestPackage(t, "sourceedit")
+	user := findStruct(t, pkg, "User")
+	fields, err := user.Fields()
+	require.NoError(t, err)
+
+	editor := gosrc.NewSourceEditor(user.File)
+	require.NoError(t, editor.AddField(user, "Email string `json:\"email\"`", "Email is an e-mail."))
+	require.NoError(t, editor.RemoveField(fields.FindByName("Legacy")))
+	require.NoError(t, editor.SetTag(fields.FindByName("ID"), "json", "id,omitempty"))
+	require.NoError(t, editor.SetTag(fields.FindByName("Name"), "json", "name"))
+	require.NoError(t, editor.SetFieldDoc(fields.FindByName("Name"), "Name is the full name."))
+	require.NoError(t, editor.SetStructDoc(user, "User is a registered user."))
+	require.NoError(t, editor.AddMethod(&user.AstTypeSpec, "func (u *User) SetName(name string) {\n\tu.Name = strings.TrimSpace(name)\n}"))
+	require.NoError(t, editor.AddImport("strings", ""))
+	require.NoError(t, editor.AddImport("fmt", ""))
+	require.NoError(t, editor.AddImport("strings", ""))
+	require.NoError(t, editor.SetFuncDoc(pkg.AllFuncs().FindByName("helper")[0], "helper helps."))
+
+	require.Error(t, editor.AddField(user, "A, int", ""))
+	require.Error(t, editor.AddMethod(&user.AstTypeSpec, "func (o *Other) M() {}"))
+	require.Error(t, editor.AddImport("fmt", "f"))
+	require.Error(t, editor.AddImport("strings", "str"))
+
+	edit, err := editor.Apply()
+	require.NoError(t, err)
+	require.Equal(t, `package sourceedit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// User is a registered user.
+//
+//gen:builder
+type User struct {
+	// ID is an identifier.
+	ID int `+"`json:\"id,omitempty\" db:\"id\"`"+` // primary key
+
+	// Name is the full name.
+	Name string `+"`json:\"name\"`"+`
+
+	// Email is an e-mail.
+	Email string `+"`json:\"email\"`"+`
+}
+
+// String just implements fmt.Stringer
+func (u User) String() string {
+	return fmt.Sprint(u.ID)
+}
+
+func (u *User) SetName(name string) {
+	u.Name = strings.TrimSpace(name)
+}
+
+/* a free-floating comment */
+
+// helper helps.
+func helper() {}
+
+func unformatted() { _ = []int{1,2,3} }
+`, string(edit.Modified))
+	require.Contains(t, edit.Diff(), "-\tLegacy bool // to be removed\n")
+
+	editor = gosrc.NewSourceEditor(user.File)
+	require.NoError(t, editor.RemoveTag(fields.FindByName("ID"), "json"))
+	require.NoError(t, editor.SetTag(fields.FindByName("ID"), "json", "x"))
+	_, err = editor.Apply()
+	require.Error(t, err)
+
+	edit, err = gosrc.NewSourceEditor(user.File).Apply()
+	require.NoError(t, err)
+	require.False(t, edit.IsChanged())
+}
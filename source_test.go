@@ -0,0 +1,68 @@
+package gosrc_test
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+// the embedded AST nodes are still available
+var (
+	_ ast.Node = (*gosrc.Field)(nil)
+	_ ast.Node = (*gosrc.Func)(nil)
+	_ ast.Node = (*gosrc.InterfaceMethod)(nil)
+	_ ast.Node = (*gosrc.Const)(nil)
+)
+
+func TestSource(t *testing.T) {
+	pkg := openTestPackage(t, "source")
+
+	point := findStruct(t, pkg, "Point")
+	require.Equal(t, 4, point.Position().Line)
+	require.Equal(t, 1, point.Position().Column)
+	require.Equal(t, 8, point.EndPosition().Line)
+	source, err := point.Source()
+	require.NoError(t, err)
+	require.Equal(t, "// Point is a point.\ntype Point struct {\n\t// X is the horizontal coordinate.\n\tX int `json:\"x\"` // in pixels\n\tY int\n}", source)
+
+	fields, err := point.Fields()
+	require.NoError(t, err)
+	require.Equal(t, 6, fields[0].Position().Line)
+	require.Equal(t, 2, fields[0].Position().Column)
+	source, err = fields[0].Source()
+	require.NoError(t, err)
+	require.Equal(t, "// X is the horizontal coordinate.\n\tX int `json:\"x\"` // in pixels", source)
+	source, err = fields[1].Source()
+	require.NoError(t, err)
+	require.Equal(t, "Y int", source)
+
+	circle := findStruct(t, pkg, "Circle")
+	require.Equal(t, 13, circle.Position().Line)
+	require.Equal(t, 2, circle.Position().Column)
+	source, err = circle.Source()
+	require.NoError(t, err)
+	require.Equal(t, "// Circle is a circle.\n\tCircle struct {\n\t\tCenter Point\n\t\tRadius int\n\t}", source)
+
+	square := findStruct(t, pkg, "Square")
+	source, err = square.Source()
+	require.NoError(t, err)
+	require.Equal(t, "Square struct{ Side int } // a square", source)
+
+	length := pkg.Funcs().FindByName("Len")[0]
+	require.Equal(t, 22, length.Position().Line)
+	require.Equal(t, 24, length.EndPosition().Line)
+	source, err = length.Source()
+	require.NoError(t, err)
+	require.Equal(t, "// Len returns the length.\nfunc (p Point) Len() int {\n\treturn p.X + p.Y\n}", source)
+
+	consts := pkg.Consts()
+	source, err = consts.FindByName("Size").Source()
+	require.NoError(t, err)
+	require.Equal(t, "// Size is a size.\nconst Size = 10 // pixels", source)
+	source, err = consts.FindByName("Small").Source()
+	require.NoError(t, err)
+	require.Equal(t, "// Small is small.\n\tSmall = 1", source)
+	require.Equal(t, 31, consts.FindByName("Small").Position().Line)
+}
@@ -15,6 +15,9 @@ type Structs []*Struct
 
 // String just implements fmt.Stringer
 func (_struct Struct) String() string {
+	if _struct.IsAnonymous() {
+		return "struct:" + _struct.path()
+	}
 	return fmt.Sprintf("struct:%s", _struct.Name())
 }
 
@@ -30,13 +33,15 @@ func (_struct *Struct) Fields() (Fields, error) {
 		if err != nil {
 			return nil, fmt.Errorf("unable to lookup type '%s': %w", field.Type, err)
 		}
-		goFields = append(goFields, &Field{
+		goField := &Field{
 			Field:     *field,
 			Struct:    _struct,
 			Names:     field.Names,
 			Index:     uint(idx),
 			TypeValue: typ,
-		})
+		}
+		goField.tags = goField.parseTags()
+		goFields = append(goFields, goField)
 	}
 	return goFields, nil
 }
@@ -0,0 +1,410 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SymbolKind is a kind of a declaration indexed by SymbolIndex.
+type SymbolKind uint
+
+const (
+	// SymbolKindUndefined is an undefined kind of a symbol.
+	SymbolKindUndefined = SymbolKind(iota)
+
+	// SymbolKindType is a kind of type definitions (see Symbol.TypeSpec).
+	SymbolKindType
+
+	// SymbolKindFunc is a kind of functions (see Symbol.Func).
+	SymbolKindFunc
+
+	// SymbolKindMethod is a kind of methods (see Symbol.Func).
+	SymbolKindMethod
+
+	// SymbolKindField is a kind of fields of structures, including
+	// fields of anonymous structures of fields (see Symbol.Field).
+	SymbolKindField
+
+	// SymbolKindInterfaceMethod is a kind of methods declared in interfaces
+	// (see Symbol.InterfaceMethod).
+	SymbolKindInterfaceMethod
+
+	// SymbolKindConst is a kind of package-level constants (see Symbol.Ident).
+	SymbolKindConst
+
+	// SymbolKindVar is a kind of package-level variables (see Symbol.Ident).
+	SymbolKindVar
+)
+
+// String just implements fmt.Stringer
+func (kind SymbolKind) String() string {
+	switch kind {
+	case SymbolKindUndefined:
+		return "undefined"
+	case SymbolKindType:
+		return "type"
+	case SymbolKindFunc:
+		return "func"
+	case SymbolKindMethod:
+		return "method"
+	case SymbolKindField:
+		return "field"
+	case SymbolKindInterfaceMethod:
+		return "interface_method"
+	case SymbolKindConst:
+		return "const"
+	case SymbolKindVar:
+		return "var"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// Symbol represents one declaration indexed by SymbolIndex.
+type Symbol struct {
+	Kind SymbolKind
+
+	// QualifiedName is the name prefixed by the package path and the
+	// names of parent declarations, like "example.com/pkg.Type.Method".
+	QualifiedName string
+
+	// ShortName is the same as QualifiedName, but with the package name
+	// instead of the package path, like "pkg.Type.Method".
+	ShortName string
+
+	Package *Package
+
+	// TypeSpec is the type definition for SymbolKindType, or the type
+	// the member belongs to for SymbolKindMethod, SymbolKindField and
+	// SymbolKindInterfaceMethod.
+	TypeSpec *AstTypeSpec
+
+	Func            *Func
+	Field           *Field
+	InterfaceMethod *InterfaceMethod
+
+	// Ident is the identifier of the declaration.
+	Ident *ast.Ident
+}
+
+// Symbols is a set of Symbol-s.
+type Symbols []*Symbol
+
+// Name returns the name of the declaration (without any prefixes).
+func (symbol Symbol) Name() string {
+	return symbol.Ident.Name
+}
+
+// String just implements fmt.Stringer
+func (symbol Symbol) String() string {
+	return fmt.Sprintf("%s %s", symbol.Kind, symbol.QualifiedName)
+}
+
+// SymbolIndex allows to find declarations by their qualified names.
+//
+// The index is not updated if the packages are modified after
+// the index was built.
+type SymbolIndex struct {
+	byQualifiedName map[string]Symbols
+	byShortName     map[string]Symbols
+
+	// sorted is sorted by QualifiedName (used for prefix search).
+	sorted Symbols
+}
+
+// NewSymbolIndex builds an index of all the declarations of the packages.
+func NewSymbolIndex(pkgs Packages) *SymbolIndex {
+	index := &SymbolIndex{
+		byQualifiedName: map[string]Symbols{},
+		byShortName:     map[string]Symbols{},
+	}
+	for _, pkg := range pkgs {
+		index.addPackage(pkg)
+	}
+	sort.SliceStable(index.sorted, func(i, j int) bool {
+		return index.sorted[i].QualifiedName < index.sorted[j].QualifiedName
+	})
+	return index
+}
+
+// qualifiedPath returns the package path used in qualified names
+// of symbols: it is different for test packages "pkg_test".
+func (pkg *Package) qualifiedPath() string {
+	pkgPath := pkg.Path()
+	if strings.HasSuffix(pkg.Name, `_test`) && !strings.HasSuffix(pkgPath, `_test`) {
+		pkgPath += `_test`
+	}
+	return pkgPath
+}
+
+func (index *SymbolIndex) add(symbol *Symbol, parent *Symbol) {
+	if symbol.Ident == nil || symbol.Ident.Name == "_" {
+		return
+	}
+	if parent == nil {
+		symbol.QualifiedName = symbol.Package.qualifiedPath() + "." + symbol.Ident.Name
+		symbol.ShortName = symbol.Package.Name + "." + symbol.Ident.Name
+	} else {
+		symbol.QualifiedName = parent.QualifiedName + "." + symbol.Ident.Name
+		symbol.ShortName = parent.ShortName + "." + symbol.Ident.Name
+	}
+	index.byQualifiedName[symbol.QualifiedName] = append(index.byQualifiedName[symbol.QualifiedName], symbol)
+	index.byShortName[symbol.ShortName] = append(index.byShortName[symbol.ShortName], symbol)
+	index.sorted = append(index.sorted, symbol)
+}
+
+func (index *SymbolIndex) addPackage(pkg *Package) {
+	typeSymbols := map[string]*Symbol{}
+	for _, file := range pkg.Files {
+		if file.Package != pkg {
+			// the file is excluded (for example, by build tags)
+			continue
+		}
+		for _, astTypeSpec := range file.AstTypeSpecs() {
+			symbol := &Symbol{
+				Kind:     SymbolKindType,
+				Package:  pkg,
+				TypeSpec: astTypeSpec,
+				Ident:    astTypeSpec.TypeSpec.Name,
+			}
+			index.add(symbol, nil)
+			typeSymbols[astTypeSpec.Name()] = symbol
+			if _struct := astTypeSpec.ToStruct(); _struct != nil {
+				index.addStructFields(pkg, _struct, symbol)
+			}
+			if iface := astTypeSpec.ToInterface(); iface != nil {
+				for _, method := range iface.ExplicitMethods() {
+					index.add(&Symbol{
+						Kind:            SymbolKindInterfaceMethod,
+						Package:         pkg,
+						TypeSpec:        astTypeSpec,
+						InterfaceMethod: method,
+						Ident:           method.Names[0],
+					}, symbol)
+				}
+			}
+		}
+		index.addValues(pkg, file)
+	}
+
+	for _, file := range pkg.Files {
+		if file.Package != pkg {
+			continue
+		}
+		for _, fn := range file.AllFuncs() {
+			if !fn.IsMethod() {
+				index.add(&Symbol{
+					Kind:    SymbolKindFunc,
+					Package: pkg,
+					Func:    fn,
+					Ident:   fn.FuncDecl.Name,
+				}, nil)
+				continue
+			}
+			parent := typeSymbols[fn.ReceiverTypeName()]
+			if parent == nil {
+				continue
+			}
+			index.add(&Symbol{
+				Kind:     SymbolKindMethod,
+				Package:  pkg,
+				TypeSpec: parent.TypeSpec,
+				Func:     fn,
+				Ident:    fn.FuncDecl.Name,
+			}, parent)
+		}
+	}
+}
+
+func (index *SymbolIndex) addStructFields(pkg *Package, _struct *Struct, parent *Symbol) {
+	fields, err := _struct.Fields()
+	if err != nil {
+		return
+	}
+	for _, field := range fields {
+		idents := field.Names
+		if len(idents) == 0 {
+			idents = []*ast.Ident{{NamePos: field.Type.Pos(), Name: field.Name()}}
+		}
+		for _, ident := range idents {
+			symbol := &Symbol{
+				Kind:     SymbolKindField,
+				Package:  pkg,
+				TypeSpec: parent.TypeSpec,
+				Field:    field,
+				Ident:    ident,
+			}
+			index.add(symbol, parent)
+			if inlineStruct := field.InlineStruct(); inlineStruct != nil {
+				index.addStructFields(pkg, inlineStruct, symbol)
+			}
+		}
+	}
+}
+
+func (index *SymbolIndex) addValues(pkg *Package, file *File) {
+	for _, decl := range file.Ast.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		var kind SymbolKind
+		switch genDecl.Tok {
+		case token.CONST:
+			kind = SymbolKindConst
+		case token.VAR:
+			kind = SymbolKindVar
+		default:
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, ident := range valueSpec.Names {
+				index.add(&Symbol{
+					Kind:    kind,
+					Package: pkg,
+					Ident:   ident,
+				}, nil)
+			}
+		}
+	}
+}
+
+// Len returns the amount of indexed symbols.
+func (index *SymbolIndex) Len() int {
+	return len(index.sorted)
+}
+
+// Lookup returns the declaration by its qualified name
+// (like "example.com/pkg.Type.Field") or by its short name (like
+// "pkg.Type.Field"). It returns nil if there is no such declaration
+// or if the short name is ambiguous (see LookupAll).
+func (index *SymbolIndex) Lookup(name string) *Symbol {
+	symbols := index.LookupAll(name)
+	if len(symbols) != 1 {
+		return nil
+	}
+	return symbols[0]
+}
+
+// LookupAll returns all the declarations with the specified qualified or
+// short name (there could be multiple packages with the same name,
+// or multiple "init" functions).
+func (index *SymbolIndex) LookupAll(name string) Symbols {
+	if symbols, ok := index.byQualifiedName[name]; ok {
+		return symbols
+	}
+	return index.byShortName[name]
+}
+
+// PrefixSearch returns all the declarations which qualified names start
+// with the prefix (in the order of qualified names).
+func (index *SymbolIndex) PrefixSearch(prefix string) Symbols {
+	start := sort.Search(len(index.sorted), func(i int) bool {
+		return index.sorted[i].QualifiedName >= prefix
+	})
+	var result Symbols
+	for _, symbol := range index.sorted[start:] {
+		if !strings.HasPrefix(symbol.QualifiedName, prefix) {
+			break
+		}
+		result = append(result, symbol)
+	}
+	return result
+}
+
+// FuzzySearch returns up to limit declarations which qualified names
+// contain all the characters of the query in the same order (case
+// insensitive), the best matches first. Matches in the name of
+// a declaration itself and consecutive matches are ranked higher.
+//
+// If limit is zero or negative, then all the matches are returned.
+func (index *SymbolIndex) FuzzySearch(query string, limit int) Symbols {
+	type scoredSymbol struct {
+		symbol *Symbol
+		score  int
+	}
+	var matches []scoredSymbol
+	for _, symbol := range index.sorted {
+		score, ok := fuzzyScore(symbol, query)
+		if !ok {
+			continue
+		}
+		matches = append(matches, scoredSymbol{symbol: symbol, score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make(Symbols, 0, len(matches))
+	for _, match := range matches {
+		result = append(result, match.symbol)
+	}
+	return result
+}
+
+func fuzzyScore(symbol *Symbol, query string) (int, bool) {
+	target := []rune(symbol.QualifiedName)
+	nameStart := len(target) - len([]rune(symbol.Name()))
+
+	score := 0
+	pos := 0
+	prevMatch := -2
+	for _, queryRune := range strings.ToLower(query) {
+		found := false
+		for ; pos < len(target); pos++ {
+			if unicode.ToLower(target[pos]) != queryRune {
+				continue
+			}
+			score++
+			if pos >= nameStart {
+				score += 2
+			}
+			if pos == prevMatch+1 {
+				score += 3
+			}
+			if pos == nameStart {
+				score += 5
+			}
+			prevMatch = pos
+			pos++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	if strings.EqualFold(symbol.Name(), query) {
+		score += 100
+	}
+	return score, true
+}
+
+// SymbolIndex returns the index of all the declarations of the packages
+// of the directory. It is built on the first call and then reused.
+func (dir *Directory) SymbolIndex() *SymbolIndex {
+	if dir.symbolIndex == nil {
+		dir.symbolIndex = NewSymbolIndex(dir.Packages)
+	}
+	return dir.symbolIndex
+}
+
+// SymbolIndex returns the index of all the declarations of the packages
+// opened so far. It is rebuilt only if new packages were opened since
+// the previous call.
+func (loader *Loader) SymbolIndex() *SymbolIndex {
+	if loader.symbolIndex == nil {
+		loader.symbolIndex = NewSymbolIndex(loader.Packages())
+	}
+	return loader.symbolIndex
+}
@@ -0,0 +1,47 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestSymbolIndex(t *testing.T) {
+	pkg := openTestPackage(t, "inline")
+	implPkg := openTestPackage(t, "implementation")
+	index := gosrc.NewSymbolIndex(gosrc.Packages{pkg, implPkg})
+
+	const pkgPath = "github.com/xaionaro-go/gosrc/testdata/inline"
+	config := index.Lookup(pkgPath + ".Config")
+	require.NotNil(t, config)
+	require.Equal(t, gosrc.SymbolKindType, config.Kind)
+	require.Equal(t, "Config", config.TypeSpec.Name())
+
+	addr := index.Lookup("inline.Config.Server.Addr")
+	require.NotNil(t, addr)
+	require.Equal(t, gosrc.SymbolKindField, addr.Kind)
+	require.Equal(t, pkgPath+".Config.Server.Addr", addr.QualifiedName)
+
+	logger := index.Lookup("inline.Config.Logger")
+	require.NotNil(t, logger)
+
+	start := index.Lookup("implementation.Plugin.Start")
+	require.NotNil(t, start)
+	require.Equal(t, gosrc.SymbolKindInterfaceMethod, start.Kind)
+
+	write := index.Lookup("implementation.Writer.Write")
+	require.NotNil(t, write)
+	require.Equal(t, gosrc.SymbolKindMethod, write.Kind)
+	require.Equal(t, "Write", write.Func.Name.Name)
+	// functions are created once per file
+	require.Same(t, write.Func, implPkg.Funcs().FindByName("Write")[0])
+
+	require.Nil(t, index.Lookup("implementation.Writer.Read"))
+
+	require.Len(t, index.PrefixSearch(pkgPath+".Config.Server."), 3)
+
+	found := index.FuzzySearch("ptrplug", 1)
+	require.Len(t, found, 1)
+	require.Equal(t, "implementation.PointerPlugin", found[0].ShortName)
+}
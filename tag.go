@@ -0,0 +1,435 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structtag"
+)
+
+// Tag is one key of a struct field tag, like `json:"name,omitempty"`.
+type Tag struct {
+	Key string
+
+	// Name is the first part of the value (like "name" in
+	// `json:"name,omitempty"`).
+	Name string
+
+	// Options are the other comma-separated parts of the value (like
+	// "omitempty" in `json:"name,omitempty"`).
+	Options []string
+}
+
+// Tags is a set of Tag-s (in the order of appearance in the struct
+// field tag).
+type Tags []*Tag
+
+// ParseTags parses a struct field tag (without the quotes), like
+// `json:"name,omitempty" yaml:"name"`. It returns an error if the tag is
+// malformed or if a key is used multiple times.
+func ParseTags(rawTag string) (Tags, error) {
+	parsed, err := structtag.Parse(rawTag)
+	if err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		// only spaces
+		return nil, nil
+	}
+	var result Tags
+	for _, tag := range parsed.Tags() {
+		if result.Get(tag.Key) != nil {
+			return nil, fmt.Errorf("duplicate key '%s'", tag.Key)
+		}
+		result = append(result, &Tag{
+			Key:     tag.Key,
+			Name:    tag.Name,
+			Options: tag.Options,
+		})
+	}
+	return result, nil
+}
+
+// Value returns the value of the tag (like "name,omitempty").
+func (tag Tag) Value() string {
+	return strings.Join(append([]string{tag.Name}, tag.Options...), ",")
+}
+
+// String just implements fmt.Stringer
+func (tag Tag) String() string {
+	return tag.Key + ":" + strconv.Quote(tag.Value())
+}
+
+// HasOption returns true if the tag has the option.
+func (tag Tag) HasOption(option string) bool {
+	for _, candidate := range tag.Options {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnored returns true if the value is "-" (a common convention of
+// encoders to skip the field; note: value "-," means name "-").
+func (tag Tag) IsIgnored() bool {
+	return tag.Name == "-" && len(tag.Options) == 0
+}
+
+// Get returns the tag with the specified key (or nil if there is no
+// such key).
+func (tags Tags) Get(key string) *Tag {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys of the tags.
+func (tags Tags) Keys() []string {
+	var result []string
+	for _, tag := range tags {
+		result = append(result, tag.Key)
+	}
+	return result
+}
+
+// String just implements fmt.Stringer
+func (tags Tags) String() string {
+	var result []string
+	for _, tag := range tags {
+		result = append(result, tag.String())
+	}
+	return strings.Join(result, " ")
+}
+
+// fieldTags is the result of parsing a struct field tag.
+type fieldTags struct {
+	tags Tags
+	err  error
+
+	// values are the values by the keys (the first one for a repeated
+	// key, like reflect.StructTag.Get does), used by Field.TagGet. It is
+	// nil if the tag cannot be parsed at all.
+	values map[string]string
+}
+
+func (field Field) parseTags() *fieldTags {
+	rawTag, err := field.RawTag()
+	if err != nil {
+		return &fieldTags{err: err}
+	}
+	result := &fieldTags{values: tagValues(rawTag)}
+	result.tags, err = ParseTags(rawTag)
+	if err != nil {
+		result.err = ErrMalformedTag{
+			Position: field.Position(),
+			Tag:      rawTag,
+			Err:      err,
+		}
+	}
+	return result
+}
+
+// parsedTags returns the tag parsed when the field was created (or parses
+// it if the field was created otherwise).
+func (field Field) parsedTags() *fieldTags {
+	if field.tags == nil {
+		return field.parseTags()
+	}
+	return field.tags
+}
+
+// RawTag returns the struct field tag without the quotes (or an empty
+// string if there is no tag).
+func (field Field) RawTag() (string, error) {
+	if field.Field.Tag == nil {
+		return "", nil
+	}
+	rawTag, err := strconv.Unquote(field.Field.Tag.Value)
+	if err != nil {
+		return "", ErrMalformedTag{
+			Position: field.Position(),
+			Tag:      field.Field.Tag.Value,
+			Err:      err,
+		}
+	}
+	return rawTag, nil
+}
+
+// Tags returns all the keys of the struct field tag (the tag is parsed
+// once, when the field is created by Struct.Fields). A malformed tag is
+// reported as ErrMalformedTag.
+func (field Field) Tags() (Tags, error) {
+	parsed := field.parsedTags()
+	return parsed.tags, parsed.err
+}
+
+// LookupTag returns the tag with the specified key (or nil if there is
+// no such key). A malformed tag is reported as ErrMalformedTag.
+func (field Field) LookupTag(key string) (*Tag, error) {
+	tags, err := field.Tags()
+	if err != nil {
+		return nil, err
+	}
+	return tags.Get(key), nil
+}
+
+// ValidateTags checks the struct field tags of all the fields of
+// the structure and returns ErrMalformedTags if some of them are
+// malformed.
+func (_struct *Struct) ValidateTags() error {
+	fields, err := _struct.Fields()
+	if err != nil {
+		return err
+	}
+	var errs ErrMalformedTags
+	for _, field := range fields {
+		_, err := field.Tags()
+		if err == nil {
+			continue
+		}
+		if errTag, ok := err.(ErrMalformedTag); ok {
+			errs = append(errs, errTag)
+			continue
+		}
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// JSONTag describes the field as it is handled by encoding/json.
+type JSONTag struct {
+	// Name is the name of the key (the name of the field if the tag
+	// does not define it).
+	Name string
+
+	// Ignored is true for `json:"-"`.
+	Ignored   bool
+	OmitEmpty bool
+	OmitZero  bool
+
+	// AsString is true for option "string" (a value is encoded as a string).
+	AsString bool
+
+	// Inline is true for embedded fields without a name in the tag: fields
+	// of such structures are promoted.
+	Inline bool
+}
+
+// JSONTag returns the description of the field as it is handled by
+// encoding/json.
+func (field Field) JSONTag() (*JSONTag, error) {
+	tag, err := field.LookupTag("json")
+	if err != nil {
+		return nil, err
+	}
+	result := &JSONTag{Name: field.Name()}
+	if tag == nil {
+		result.Inline = field.isEmbeddedStruct()
+		return result, nil
+	}
+	if tag.IsIgnored() {
+		result.Ignored = true
+		return result, nil
+	}
+	if tag.Name != "" {
+		result.Name = tag.Name
+	} else {
+		result.Inline = field.isEmbeddedStruct()
+	}
+	result.OmitEmpty = tag.HasOption("omitempty")
+	result.OmitZero = tag.HasOption("omitzero")
+	result.AsString = tag.HasOption("string")
+	return result, nil
+}
+
+// isEmbeddedStruct returns true if the field is an embedded structure
+// (or a pointer to a structure).
+func (field Field) isEmbeddedStruct() bool {
+	if !field.IsEmbedded() {
+		return false
+	}
+	ref := field.TypeRef().Deref()
+	if ref == nil || ref.Type == nil {
+		return false
+	}
+	_, ok := ref.Type.Underlying().(*types.Struct)
+	return ok
+}
+
+// YAMLTag describes the field as it is handled by YAML encoders
+// (like gopkg.in/yaml.v3).
+type YAMLTag struct {
+	// Name is the name of the key (the lower-cased name of the field if
+	// the tag does not define it).
+	Name string
+
+	// Ignored is true for `yaml:"-"`.
+	Ignored   bool
+	OmitEmpty bool
+	Flow      bool
+
+	// Inline is true for option "inline": fields of such structure
+	// (or items of such map) are promoted.
+	Inline bool
+}
+
+// YAMLTag returns the description of the field as it is handled by YAML
+// encoders.
+func (field Field) YAMLTag() (*YAMLTag, error) {
+	tag, err := field.LookupTag("yaml")
+	if err != nil {
+		return nil, err
+	}
+	result := &YAMLTag{Name: strings.ToLower(field.Name())}
+	if tag == nil {
+		return result, nil
+	}
+	if tag.IsIgnored() {
+		result.Ignored = true
+		return result, nil
+	}
+	if tag.Name != "" {
+		result.Name = tag.Name
+	}
+	result.OmitEmpty = tag.HasOption("omitempty")
+	result.Flow = tag.HasOption("flow")
+	result.Inline = tag.HasOption("inline")
+	return result, nil
+}
+
+// DBTag describes the field as it is handled by SQL mappers (like sqlx).
+type DBTag struct {
+	// Name is the name of the column (the lower-cased name of the field
+	// if the tag does not define it).
+	Name string
+
+	// Ignored is true for `db:"-"`.
+	Ignored bool
+}
+
+// DBTag returns the description of the field as it is handled by SQL
+// mappers.
+func (field Field) DBTag() (*DBTag, error) {
+	tag, err := field.LookupTag("db")
+	if err != nil {
+		return nil, err
+	}
+	result := &DBTag{Name: strings.ToLower(field.Name())}
+	if tag == nil {
+		return result, nil
+	}
+	if tag.IsIgnored() {
+		result.Ignored = true
+		return result, nil
+	}
+	if tag.Name != "" {
+		result.Name = tag.Name
+	}
+	return result, nil
+}
+
+// EnvTag describes the field as it is handled by environment variable
+// parsers (like github.com/caarlos0/env), for example
+// `env:"PORT,required" envDefault:"8080"`.
+type EnvTag struct {
+	// Name is the name of the environment variable.
+	Name string
+
+	Required bool
+	NotEmpty bool
+	File     bool
+	Expand   bool
+	Unset    bool
+
+	// Default is the value of key "envDefault" (see HasDefault).
+	Default    string
+	HasDefault bool
+}
+
+// EnvTag returns the description of the field as it is handled by
+// environment variable parsers, or nil if the field has no "env" key.
+func (field Field) EnvTag() (*EnvTag, error) {
+	tags, err := field.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tag := tags.Get("env")
+	if tag == nil {
+		return nil, nil
+	}
+	result := &EnvTag{
+		Name:     tag.Name,
+		Required: tag.HasOption("required"),
+		NotEmpty: tag.HasOption("notEmpty"),
+		File:     tag.HasOption("file"),
+		Expand:   tag.HasOption("expand"),
+		Unset:    tag.HasOption("unset"),
+	}
+	if defaultTag := tags.Get("envDefault"); defaultTag != nil {
+		result.Default = defaultTag.Value()
+		result.HasDefault = true
+	}
+	return result, nil
+}
+
+// ValidateRule is one rule of a "validate" tag, like "min=1".
+type ValidateRule struct {
+	Name  string
+	Param string
+}
+
+// ValidateTag describes the field as it is handled by
+// github.com/go-playground/validator, for example
+// `validate:"required,min=1,max=10"`.
+type ValidateTag struct {
+	// Skip is true for `validate:"-"`.
+	Skip bool
+
+	Rules []ValidateRule
+}
+
+// HasRule returns true if there is a rule with the specified name.
+func (tag ValidateTag) HasRule(name string) bool {
+	for _, rule := range tag.Rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTag returns the description of the field as it is handled by
+// the validator, or nil if the field has no "validate" key.
+func (field Field) ValidateTag() (*ValidateTag, error) {
+	tag, err := field.LookupTag("validate")
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, nil
+	}
+	if tag.IsIgnored() {
+		return &ValidateTag{Skip: true}, nil
+	}
+	result := &ValidateTag{}
+	for _, part := range append([]string{tag.Name}, tag.Options...) {
+		if part == "" {
+			continue
+		}
+		rule := ValidateRule{Name: part}
+		if idx := strings.IndexByte(part, '='); idx >= 0 {
+			rule.Name, rule.Param = part[:idx], part[idx+1:]
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
@@ -0,0 +1,574 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TagDiagnosticKind is a kind of a problem found by TagLinter.
+type TagDiagnosticKind uint
+
+const (
+	// TagDiagnosticKindUndefined is an undefined kind of a problem.
+	TagDiagnosticKindUndefined = TagDiagnosticKind(iota)
+
+	// TagDiagnosticKindMalformed is a struct field tag which cannot
+	// be parsed.
+	TagDiagnosticKindMalformed
+
+	// TagDiagnosticKindDuplicateName is a name used by multiple fields
+	// of a structure (including promoted fields) at the same depth for
+	// the same key, so an encoder ignores all of them.
+	TagDiagnosticKindDuplicateName
+
+	// TagDiagnosticKindUnexportedField is a tag on an unexported field,
+	// which is ignored by encoders.
+	TagDiagnosticKindUnexportedField
+
+	// TagDiagnosticKindUnknownOption is an option unknown for the key
+	// (usually a typo, like "omitemtpy").
+	TagDiagnosticKindUnknownOption
+
+	// TagDiagnosticKindInconsistentNaming is a name, which does not follow
+	// the naming convention (like snake_case or camelCase) used by
+	// the other fields of the structure for the same key.
+	TagDiagnosticKindInconsistentNaming
+
+	// TagDiagnosticKindKeyMismatch is a field with different names for keys
+	// which are expected to match (like "json" and "yaml").
+	TagDiagnosticKindKeyMismatch
+)
+
+// String just implements fmt.Stringer
+func (kind TagDiagnosticKind) String() string {
+	switch kind {
+	case TagDiagnosticKindUndefined:
+		return "undefined"
+	case TagDiagnosticKindMalformed:
+		return "malformed"
+	case TagDiagnosticKindDuplicateName:
+		return "duplicate_name"
+	case TagDiagnosticKindUnexportedField:
+		return "unexported_field"
+	case TagDiagnosticKindUnknownOption:
+		return "unknown_option"
+	case TagDiagnosticKindInconsistentNaming:
+		return "inconsistent_naming"
+	case TagDiagnosticKindKeyMismatch:
+		return "key_mismatch"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// TagDiagnostic is one problem with struct field tags found by TagLinter.
+type TagDiagnostic struct {
+	Kind     TagDiagnosticKind
+	Position token.Position
+	Struct   *Struct
+
+	// Field is the field with the problem. It is nil for promoted fields
+	// (see Position).
+	Field *Field
+
+	// Key is the tag key (like "json"), if applicable.
+	Key string
+
+	Message string
+}
+
+// TagDiagnostics is a set of TagDiagnostic-s.
+type TagDiagnostics []*TagDiagnostic
+
+// String just implements fmt.Stringer
+func (diag TagDiagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", diag.Position, diag.Kind, diag.Message)
+}
+
+// FilterByKind returns only the diagnostics of the specified kinds.
+func (diags TagDiagnostics) FilterByKind(kinds ...TagDiagnosticKind) TagDiagnostics {
+	var result TagDiagnostics
+	for _, diag := range diags {
+		for _, kind := range kinds {
+			if diag.Kind == kind {
+				result = append(result, diag)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// TagLinter checks struct field tags of structures.
+type TagLinter struct {
+	// NameKeys are the keys which values start with a name of the field
+	// for an encoder. These names are checked for duplicates and for
+	// naming conventions, and tags with these keys on unexported fields
+	// are reported.
+	NameKeys []string
+
+	// KnownOptions are the options known for keys. Options of keys
+	// which are not in the map are not checked.
+	KnownOptions map[string][]string
+
+	// MatchingKeys are the keys which names are expected to be equal
+	// if a field has tags with multiple of them.
+	MatchingKeys []string
+}
+
+// NewTagLinter returns a new instance of TagLinter with the conventions
+// of encoding/json, encoding/xml, YAML, TOML, BSON, mapstructure, SQL
+// mappers and environment variable parsers.
+func NewTagLinter() *TagLinter {
+	return &TagLinter{
+		NameKeys: []string{"json", "yaml", "xml", "toml", "bson", "mapstructure", "db", "env"},
+		KnownOptions: map[string][]string{
+			"json":         {"omitempty", "omitzero", "string"},
+			"yaml":         {"omitempty", "flow", "inline"},
+			"xml":          {"attr", "chardata", "cdata", "innerxml", "comment", "any", "omitempty"},
+			"toml":         {"omitempty", "omitzero", "inline"},
+			"bson":         {"omitempty", "minsize", "truncate", "inline"},
+			"mapstructure": {"omitempty", "squash", "remain"},
+			"db":           {},
+			"env":          {"required", "notEmpty", "file", "expand", "unset", "init"},
+		},
+		MatchingKeys: []string{"json", "yaml", "toml", "mapstructure"},
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, candidate := range list {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks all the structures (including anonymous structures
+// of fields) of the packages. The packages should be opened without
+// "onlyFiles".
+func (linter *TagLinter) Lint(pkgs Packages) (TagDiagnostics, error) {
+	var result TagDiagnostics
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			if file.Package != pkg {
+				continue
+			}
+			for _, _struct := range file.Structs() {
+				diags, err := linter.lintStructRecursive(_struct)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, diags...)
+			}
+		}
+	}
+	sortTagDiagnostics(result)
+	return result, nil
+}
+
+func (linter *TagLinter) lintStructRecursive(_struct *Struct) (TagDiagnostics, error) {
+	result, err := linter.LintStruct(_struct)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		inlineStruct := field.InlineStruct()
+		if inlineStruct == nil {
+			continue
+		}
+		diags, err := linter.lintStructRecursive(inlineStruct)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, diags...)
+	}
+	return result, nil
+}
+
+// LintStruct checks the fields of the structure (anonymous structures
+// of fields are not checked, see Lint).
+func (linter *TagLinter) LintStruct(_struct *Struct) (TagDiagnostics, error) {
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+
+	var result TagDiagnostics
+	report := func(kind TagDiagnosticKind, field *Field, key string, format string, args ...interface{}) {
+		result = append(result, &TagDiagnostic{
+			Kind:     kind,
+			Position: field.Position(),
+			Struct:   _struct,
+			Field:    field,
+			Key:      key,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, field := range fields {
+		tags, err := field.Tags()
+		if err != nil {
+			report(TagDiagnosticKindMalformed, field, "", "%v", unwrapMalformedTag(err))
+			continue
+		}
+		linter.lintFieldTags(field, tags, report)
+	}
+	linter.lintNaming(fields, report)
+
+	duplicates, err := linter.lintDuplicates(_struct)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, duplicates...)
+	sortTagDiagnostics(result)
+	return result, nil
+}
+
+func unwrapMalformedTag(err error) error {
+	if errTag, ok := err.(ErrMalformedTag); ok {
+		return errTag.Err
+	}
+	return err
+}
+
+type tagReportFunc func(kind TagDiagnosticKind, field *Field, key string, format string, args ...interface{})
+
+func (linter *TagLinter) lintFieldTags(field *Field, tags Tags, report tagReportFunc) {
+	exported := field.IsEmbedded() || (len(field.Names) > 0 && field.Names[0].IsExported())
+	for _, tag := range tags {
+		if !exported && containsString(linter.NameKeys, tag.Key) && !tag.IsIgnored() {
+			report(TagDiagnosticKindUnexportedField, field, tag.Key,
+				"field '%s' is unexported, so tag key '%s' has no effect", field.Name(), tag.Key)
+		}
+		knownOptions, ok := linter.KnownOptions[tag.Key]
+		if !ok {
+			continue
+		}
+		for _, option := range tag.Options {
+			if option == "" || containsString(knownOptions, option) {
+				continue
+			}
+			report(TagDiagnosticKindUnknownOption, field, tag.Key,
+				"unknown option '%s' of tag key '%s' of field '%s'", option, tag.Key, field.Name())
+		}
+	}
+
+	var (
+		firstKey  string
+		firstName string
+	)
+	for _, key := range linter.MatchingKeys {
+		tag := tags.Get(key)
+		if tag == nil || tag.Name == "" || tag.IsIgnored() {
+			continue
+		}
+		if firstKey == "" {
+			firstKey, firstName = key, tag.Name
+			continue
+		}
+		if tag.Name != firstName {
+			report(TagDiagnosticKindKeyMismatch, field, key,
+				"field '%s' has name '%s' for tag key '%s', but '%s' for '%s'",
+				field.Name(), tag.Name, key, firstName, firstKey)
+		}
+	}
+}
+
+// namingStyle is a naming convention of a name in a tag.
+type namingStyle string
+
+const (
+	namingStyleUnknown        = namingStyle("")
+	namingStyleLower          = namingStyle("lowercase")
+	namingStyleUpper          = namingStyle("UPPERCASE")
+	namingStyleSnake          = namingStyle("snake_case")
+	namingStyleScreamingSnake = namingStyle("SCREAMING_SNAKE_CASE")
+	namingStyleKebab          = namingStyle("kebab-case")
+	namingStyleCamel          = namingStyle("camelCase")
+	namingStylePascal         = namingStyle("PascalCase")
+)
+
+func namingStyleOf(name string) namingStyle {
+	var hasLower, hasUpper bool
+	for _, r := range name {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		}
+	}
+	hasUnderscore := strings.Contains(name, "_")
+	hasDash := strings.Contains(name, "-")
+	switch {
+	case hasUnderscore && hasDash:
+		return namingStyleUnknown
+	case hasDash:
+		if hasUpper {
+			return namingStyleUnknown
+		}
+		return namingStyleKebab
+	case hasUnderscore:
+		switch {
+		case hasLower && !hasUpper:
+			return namingStyleSnake
+		case hasUpper && !hasLower:
+			return namingStyleScreamingSnake
+		default:
+			return namingStyleUnknown
+		}
+	case !hasUpper:
+		return namingStyleLower
+	case !hasLower:
+		return namingStyleUpper
+	case unicode.IsUpper([]rune(name)[0]):
+		return namingStylePascal
+	default:
+		return namingStyleCamel
+	}
+}
+
+// isCompatible returns true if a name of the ambiguous style (like "name"
+// or "ID") could be a name of the other style.
+func (style namingStyle) isCompatible(other namingStyle) bool {
+	switch style {
+	case other:
+		return true
+	case namingStyleLower:
+		return other == namingStyleSnake || other == namingStyleKebab || other == namingStyleCamel
+	case namingStyleUpper:
+		return other == namingStyleScreamingSnake
+	default:
+		return false
+	}
+}
+
+func (linter *TagLinter) lintNaming(fields Fields, report tagReportFunc) {
+	for _, key := range linter.NameKeys {
+		type namedField struct {
+			field *Field
+			name  string
+			style namingStyle
+		}
+		var namedFields []namedField
+		counts := map[namingStyle]int{}
+		var styles []namingStyle
+		for _, field := range fields {
+			tag, err := field.LookupTag(key)
+			if err != nil || tag == nil || tag.Name == "" || tag.IsIgnored() {
+				continue
+			}
+			style := namingStyleOf(tag.Name)
+			namedFields = append(namedFields, namedField{field: field, name: tag.Name, style: style})
+			if style == namingStyleUnknown || style == namingStyleLower || style == namingStyleUpper {
+				continue
+			}
+			if counts[style] == 0 {
+				styles = append(styles, style)
+			}
+			counts[style]++
+		}
+		if len(styles) == 0 {
+			continue
+		}
+
+		// the most used style (the first one for ties)
+		mainStyle := styles[0]
+		for _, style := range styles[1:] {
+			if counts[style] > counts[mainStyle] {
+				mainStyle = style
+			}
+		}
+		for _, item := range namedFields {
+			if item.style.isCompatible(mainStyle) {
+				continue
+			}
+			report(TagDiagnosticKindInconsistentNaming, item.field, key,
+				"name '%s' of field '%s' for tag key '%s' does not follow %s used by the other fields",
+				item.name, item.field.Name(), key, mainStyle)
+		}
+	}
+}
+
+// lintDuplicates finds fields of the structure (including promoted
+// fields), which have the same names for an encoder and none of which
+// is selected by it.
+//
+// Encoders follow the rules of encoding/json: a field at a shallower
+// depth hides the deeper ones (it is not a problem), and among fields
+// at the same depth a single field with a name in the tag wins.
+func (linter *TagLinter) lintDuplicates(_struct *Struct) (TagDiagnostics, error) {
+	flatFields, err := _struct.FlatFields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+	fields, err := _struct.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields of %s: %w", _struct, err)
+	}
+
+	var result TagDiagnostics
+	for _, key := range linter.NameKeys {
+		if key == "env" {
+			// environment variables of nested structures are usually prefixed
+			continue
+		}
+		// the key is checked only if it is used in the structure, otherwise
+		// the structure is probably not encoded with it
+		used := false
+		for _, flatField := range flatFields {
+			if flatFieldTag(flatField.Tag, key) != nil {
+				used = true
+				break
+			}
+		}
+		if !used {
+			continue
+		}
+
+		var names []string
+		byName := map[string]FlatFields{}
+		for _, flatField := range flatFields {
+			name, ok := encodedFieldName(flatField, key)
+			if !ok {
+				continue
+			}
+			if _, ok := byName[name]; !ok {
+				names = append(names, name)
+			}
+			byName[name] = append(byName[name], flatField)
+		}
+		for _, name := range names {
+			conflicting := dominantFlatFields(byName[name], key)
+			if len(conflicting) < 2 {
+				continue
+			}
+			first := conflicting[0]
+			for _, flatField := range conflicting[1:] {
+				// the diagnostic is placed at the field of the structure
+				// itself (the embedding one for promoted fields)
+				field := fields.findByFieldIndex(flatField.Index[0])
+				diag := &TagDiagnostic{
+					Kind:     TagDiagnosticKindDuplicateName,
+					Position: _struct.Position(),
+					Struct:   _struct,
+					Field:    field,
+					Key:      key,
+					Message: fmt.Sprintf("field '%s' has the same name '%s' for tag key '%s' as field '%s'",
+						flatField.AccessPath(), name, key, first.AccessPath()),
+				}
+				if field != nil {
+					diag.Position = field.Position()
+				}
+				result = append(result, diag)
+			}
+		}
+	}
+	return result, nil
+}
+
+// dominantFlatFields returns the fields (having the same encoded name)
+// an encoder using the tag key cannot choose from. It is empty or
+// a single field if there is no conflict.
+func dominantFlatFields(flatFields FlatFields, key string) FlatFields {
+	minDepth := -1
+	for _, flatField := range flatFields {
+		if minDepth < 0 || flatField.Depth() < minDepth {
+			minDepth = flatField.Depth()
+		}
+	}
+	var shallowest, tagged FlatFields
+	for _, flatField := range flatFields {
+		if flatField.Depth() != minDepth {
+			continue
+		}
+		shallowest = append(shallowest, flatField)
+		if tag := flatFieldTag(flatField.Tag, key); tag != nil && tag.Name != "" {
+			tagged = append(tagged, flatField)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged
+	}
+	return shallowest
+}
+
+// explicitInlineKeys are the keys of encoders, which promote fields
+// of embedded structures only with option "inline" (or "squash").
+var explicitInlineKeys = []string{"yaml", "bson", "mapstructure"}
+
+// isInlinedBy returns true if the fields of the embedded structure with
+// the tag are promoted by an encoder using the tag key.
+func isInlinedBy(tag *Tag, key string) bool {
+	if tag != nil && (tag.HasOption("inline") || tag.HasOption("squash")) {
+		return true
+	}
+	if containsString(explicitInlineKeys, key) {
+		return false
+	}
+	return tag == nil || tag.Name == ""
+}
+
+// encodedFieldName returns the name of the field for an encoder using
+// the tag key (and false if the field is not encoded by its name).
+func encodedFieldName(flatField *FlatField, key string) (string, bool) {
+	for _, parentTag := range flatField.PathTags[:len(flatField.PathTags)-1] {
+		if !isInlinedBy(flatFieldTag(parentTag, key), key) {
+			return "", false
+		}
+	}
+	tag := flatFieldTag(flatField.Tag, key)
+	if tag != nil && tag.IsIgnored() {
+		return "", false
+	}
+	if flatField.Var.Embedded() && isInlinedBy(tag, key) {
+		embeddedType := flatField.Var.Type()
+		if pointer, ok := embeddedType.(*types.Pointer); ok {
+			embeddedType = pointer.Elem()
+		}
+		if _, ok := embeddedType.Underlying().(*types.Struct); ok {
+			// the fields of the structure are promoted instead
+			return "", false
+		}
+	}
+	if !flatField.Var.Exported() {
+		return "", false
+	}
+	if tag != nil && tag.Name != "" {
+		return tag.Name, true
+	}
+	switch key {
+	case "json", "xml":
+		return flatField.Name(), true
+	default:
+		return strings.ToLower(flatField.Name()), true
+	}
+}
+
+func flatFieldTag(rawTag, key string) *Tag {
+	tags, err := ParseTags(rawTag)
+	if err != nil {
+		return nil
+	}
+	return tags.Get(key)
+}
+
+func sortTagDiagnostics(diags TagDiagnostics) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Position, diags[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+}
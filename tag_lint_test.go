@@ -0,0 +1,50 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestTagLinter(t *testing.T) {
+	pkg := openTestPackage(t, "taglint")
+	linter := gosrc.NewTagLinter()
+
+	diags, err := linter.LintStruct(findStruct(t, pkg, "Clean"))
+	require.NoError(t, err)
+	require.Empty(t, diags)
+
+	diags, err = linter.Lint(gosrc.Packages{pkg})
+	require.NoError(t, err)
+	var kinds []gosrc.TagDiagnosticKind
+	var lines []int
+	for _, diag := range diags {
+		kinds = append(kinds, diag.Kind)
+		lines = append(lines, diag.Position.Line)
+	}
+	require.Equal(t, []gosrc.TagDiagnosticKind{
+		gosrc.TagDiagnosticKindUnknownOption,
+		gosrc.TagDiagnosticKindKeyMismatch,
+		gosrc.TagDiagnosticKindInconsistentNaming,
+		gosrc.TagDiagnosticKindUnexportedField,
+		gosrc.TagDiagnosticKindMalformed,
+		gosrc.TagDiagnosticKindDuplicateName,
+		gosrc.TagDiagnosticKindDuplicateName,
+	}, kinds, "%v", diags)
+	require.Equal(t, []int{13, 14, 15, 16, 18, 21, 33}, lines)
+	require.Equal(t, "Name", diags[0].Field.Name())
+
+	// "UserID" hides "Meta.ID", but "Meta.Created" and "Audit.Created"
+	// are at the same depth
+	duplicate := diags[6]
+	require.Equal(t, "Audit", duplicate.Field.Name())
+	require.Equal(t, "json", duplicate.Key)
+	require.Contains(t, duplicate.Message, "'Audit.Created'")
+	require.Contains(t, duplicate.Message, "'Meta.Created'")
+
+	linter.KnownOptions["json"] = append(linter.KnownOptions["json"], "omitemtpy")
+	diags, err = linter.Lint(gosrc.Packages{pkg})
+	require.NoError(t, err)
+	require.Empty(t, diags.FilterByKind(gosrc.TagDiagnosticKindUnknownOption))
+}
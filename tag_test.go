@@ -0,0 +1,92 @@
+package gosrc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestTags(t *testing.T) {
+	pkg := openTestPackage(t, "tags")
+	config := findStruct(t, pkg, "Config")
+	fields, err := config.Fields()
+	require.NoError(t, err)
+
+	base, err := fields[0].JSONTag()
+	require.NoError(t, err)
+	require.Equal(t, &gosrc.JSONTag{Name: "Base", Inline: true}, base)
+
+	tags, err := fields[1].Tags()
+	require.NoError(t, err)
+	require.Equal(t, []string{"json", "yaml", "db"}, tags.Keys())
+	require.Equal(t, "name", tags.Get("json").Name)
+	require.Equal(t, []string{"omitempty"}, tags.Get("json").Options)
+	require.Equal(t, `json:"name,omitempty"`, tags.Get("json").String())
+	jsonTag, err := fields[1].JSONTag()
+	require.NoError(t, err)
+	require.Equal(t, &gosrc.JSONTag{Name: "name", OmitEmpty: true}, jsonTag)
+	yamlTag, err := fields[1].YAMLTag()
+	require.NoError(t, err)
+	require.Equal(t, &gosrc.YAMLTag{Name: "name", Flow: true}, yamlTag)
+	dbTag, err := fields[1].DBTag()
+	require.NoError(t, err)
+	require.True(t, dbTag.Ignored)
+
+	envTag, err := fields[2].EnvTag()
+	require.NoError(t, err)
+	require.Equal(t, &gosrc.EnvTag{Name: "PORT", Required: true, Default: "8080", HasDefault: true}, envTag)
+	validateTag, err := fields[2].ValidateTag()
+	require.NoError(t, err)
+	require.True(t, validateTag.HasRule("required"))
+	require.Equal(t, gosrc.ValidateRule{Name: "max", Param: "65535"}, validateTag.Rules[2])
+	dbTag, err = fields[2].DBTag()
+	require.NoError(t, err)
+	require.Equal(t, "port", dbTag.Name)
+
+	jsonTag, err = fields[3].JSONTag()
+	require.NoError(t, err)
+	require.True(t, jsonTag.Ignored)
+	validateTag, err = fields[3].ValidateTag()
+	require.NoError(t, err)
+	require.True(t, validateTag.Skip)
+	envTag, err = fields[3].EnvTag()
+	require.NoError(t, err)
+	require.Nil(t, envTag)
+
+	yamlTag, err = fields[4].YAMLTag()
+	require.NoError(t, err)
+	require.Equal(t, &gosrc.YAMLTag{Name: "hosts", Inline: true}, yamlTag)
+
+	jsonTag, err = fields[5].JSONTag()
+	require.NoError(t, err)
+	require.True(t, jsonTag.AsString)
+
+	_, err = fields[6].Tags()
+	var errTag gosrc.ErrMalformedTag
+	require.True(t, errors.As(err, &errTag))
+	require.Equal(t, 16, errTag.Position.Line)
+	_, ok := fields[6].TagGet("json")
+	require.False(t, ok)
+
+	_, err = fields[7].LookupTag("json")
+	require.ErrorContains(t, err, "duplicate key 'json'")
+	value, ok := fields[7].TagGet("json")
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+	flatFields, err := config.FlatFields()
+	require.NoError(t, err)
+	value, ok = flatFields.FindByName("Repeated").TagGet("json")
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+
+	value, ok = fields[8].TagGet("json")
+	require.True(t, ok)
+	require.Equal(t, "quoted", value)
+
+	err = config.ValidateTags()
+	var errTags gosrc.ErrMalformedTags
+	require.True(t, errors.As(err, &errTags))
+	require.Len(t, errTags, 2)
+}
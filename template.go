@@ -0,0 +1,388 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Template is a text/template generating the body of a GeneratedFile.
+//
+// Templates have access to the helper functions (see TemplateFuncs)
+// and get TemplateData as the data.
+type Template struct {
+	template *template.Template
+}
+
+// TemplateData is the data passed to a Template.
+type TemplateData struct {
+	// Package is the package the file is generated for.
+	Package *Package
+
+	// File is the file being generated.
+	File *GeneratedFile
+
+	// Struct is the structure the template is executed for (see
+	// Template.GenerateForDirective), or nil.
+	Struct *Struct
+
+	// Directive is the directive of Struct (see
+	// Template.GenerateForDirective), or nil.
+	Directive *Directive
+
+	// Values are arbitrary values passed by the generator.
+	Values map[string]interface{}
+}
+
+// Structs returns all the structures of the package.
+func (data TemplateData) Structs() Structs {
+	return data.Package.Structs()
+}
+
+// Funcs returns all the functions of the package (including methods).
+func (data TemplateData) Funcs() Funcs {
+	return data.Package.AllFuncs()
+}
+
+// ParseTemplate parses the template. The name is used in error messages
+// (like a file name).
+func ParseTemplate(name, text string) (*Template, error) {
+	parsed, err := template.New(name).Funcs(TemplateFuncs(nil)).Parse(text)
+	if err != nil {
+		return nil, newErrTemplate(name, err)
+	}
+	return &Template{template: parsed}, nil
+}
+
+// ParseTemplateFile parses the template from the file, see ParseTemplate.
+func ParseTemplateFile(path string) (*Template, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read template file '%s': %w", path, err)
+	}
+	return ParseTemplate(path, string(text))
+}
+
+// Execute executes the template with the data and appends the result to
+// the body of the file.
+//
+// The helper functions (see TemplateFuncs) qualify types relatively to
+// the file and import the packages into it.
+func (tmpl *Template) Execute(file *GeneratedFile, data interface{}) error {
+	executable, err := tmpl.template.Clone()
+	if err != nil {
+		return fmt.Errorf("unable to clone template '%s': %w", tmpl.template.Name(), err)
+	}
+	executable.Funcs(TemplateFuncs(file))
+
+	var result bytes.Buffer
+	if err := executable.Execute(&result, data); err != nil {
+		return newErrTemplate(tmpl.template.Name(), err)
+	}
+	_, err = file.Write(result.Bytes())
+	return err
+}
+
+// Generate executes the template once for the package and returns
+// the generated file.
+func (tmpl *Template) Generate(pkg *Package, generator string, values map[string]interface{}) (*GeneratedFile, error) {
+	file := pkg.NewGeneratedFile(generator)
+	err := tmpl.Execute(file, TemplateData{
+		Package: pkg,
+		File:    file,
+		Values:  values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// GenerateForDirective executes the template for each structure of
+// the package having the directive (like "gen:builder") and returns
+// the generated file (or nil if there are no such structures).
+func (tmpl *Template) GenerateForDirective(pkg *Package, directiveName, generator string, values map[string]interface{}) (*GeneratedFile, error) {
+	var file *GeneratedFile
+	for _, _struct := range pkg.Structs() {
+		directives, err := _struct.Directives()
+		if err != nil {
+			return nil, err
+		}
+		directive := directives.FindByName(directiveName)
+		if directive == nil {
+			continue
+		}
+		if file == nil {
+			file = pkg.NewGeneratedFile(generator)
+		}
+		err = tmpl.Execute(file, TemplateData{
+			Package:   pkg,
+			File:      file,
+			Struct:    _struct,
+			Directive: directive,
+			Values:    values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to execute template for %s: %w", _struct, err)
+		}
+	}
+	return file, nil
+}
+
+// TemplateFuncs returns the helper functions of templates:
+//
+//   - "qualify": the type (of a types.Type, a Field or a Struct) as it
+//     should be referenced in the file, like "*pkg.Name";
+//   - "qualifiedName": "pkg.Name" by the package path and the name;
+//   - "import": imports the package by the path and returns its name;
+//   - "zeroValue": the zero value expression of the type, like "0",
+//     `""`, "nil" or "pkg.Name{}";
+//   - "receiverName": the conventional receiver name for the type (or for
+//     the type name), like "u" for "User";
+//   - "camelCase", "pascalCase", "snakeCase", "kebabCase", "lower",
+//     "upper": case conversions ("HTTPServer" -> "httpServer",
+//     "HTTPServer", "http_server", "http-server", ...);
+//   - "tag": the value of the field tag by the key (or an empty string);
+//   - "tagName": the name part of the field tag by the key;
+//   - "quote": a Go string literal.
+//
+// The file could be nil only for parsing templates.
+func TemplateFuncs(file *GeneratedFile) template.FuncMap {
+	return template.FuncMap{
+		"qualify": func(v interface{}) (string, error) {
+			typ, err := templateTypeOf(v)
+			if err != nil {
+				return "", err
+			}
+			return file.TypeString(typ), nil
+		},
+		"qualifiedName": func(pkgPath, name string) string {
+			return file.QualifiedName(pkgPath, name)
+		},
+		"import": func(pkgPath string) string {
+			return file.Import(pkgPath)
+		},
+		"zeroValue": func(v interface{}) (string, error) {
+			typ, err := templateTypeOf(v)
+			if err != nil {
+				return "", err
+			}
+			return zeroValue(file, typ), nil
+		},
+		"receiverName": func(v interface{}) (string, error) {
+			name, err := templateTypeNameOf(v)
+			if err != nil {
+				return "", err
+			}
+			return receiverName(name), nil
+		},
+		"camelCase":  CamelCase,
+		"pascalCase": PascalCase,
+		"snakeCase":  SnakeCase,
+		"kebabCase":  KebabCase,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"tag": func(field *Field, key string) (string, error) {
+			tag, err := field.LookupTag(key)
+			if err != nil || tag == nil {
+				return "", err
+			}
+			return tag.Value(), nil
+		},
+		"tagName": func(field *Field, key string) (string, error) {
+			tag, err := field.LookupTag(key)
+			if err != nil || tag == nil {
+				return "", err
+			}
+			return tag.Name, nil
+		},
+		"quote": strconv.Quote,
+	}
+}
+
+func templateTypeOf(v interface{}) (types.Type, error) {
+	var typ types.Type
+	switch v := v.(type) {
+	case types.Type:
+		typ = v
+	case *Field:
+		typ = v.TypeValue.Type
+	case Field:
+		typ = v.TypeValue.Type
+	case *Struct:
+		typ = v.Type()
+	case *AstTypeSpec:
+		typ = v.Type()
+	case types.Object:
+		typ = v.Type()
+	default:
+		return nil, fmt.Errorf("expected a type, a field or a structure, got %T", v)
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("no type information for %v", v)
+	}
+	return typ, nil
+}
+
+func templateTypeNameOf(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case *Struct:
+		return v.Name(), nil
+	case *AstTypeSpec:
+		return v.Name(), nil
+	}
+	typ, err := templateTypeOf(v)
+	if err != nil {
+		return "", err
+	}
+	for {
+		switch casted := typ.(type) {
+		case *types.Pointer:
+			typ = casted.Elem()
+			continue
+		case *types.Named:
+			return casted.Obj().Name(), nil
+		}
+		return "", fmt.Errorf("type %s has no name", typ)
+	}
+}
+
+// zeroValue returns the expression of the zero value of the type.
+func zeroValue(file *GeneratedFile, typ types.Type) string {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return "*new(" + file.TypeString(typ) + ")"
+	}
+	switch underlying := typ.Underlying().(type) {
+	case *types.Basic:
+		info := underlying.Info()
+		switch {
+		case info&types.IsBoolean != 0:
+			return "false"
+		case info&types.IsString != 0:
+			return `""`
+		case info&types.IsNumeric != 0:
+			return "0"
+		}
+		return "nil"
+	case *types.Struct, *types.Array:
+		return file.TypeString(typ) + "{}"
+	default:
+		return "nil"
+	}
+}
+
+// receiverName returns the conventional name of a receiver of the type
+// (the lower-cased first letter of the name).
+func receiverName(typeName string) string {
+	for _, r := range typeName {
+		return string(unicode.ToLower(r))
+	}
+	return "_"
+}
+
+// splitWords splits an identifier into words: by underscores, dashes,
+// spaces and changes of the case ("HTTPServerID_v2" -> "HTTP", "Server",
+// "ID", "v2").
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(s)
+	for idx, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prev := word[len(word)-1]
+			isNextLower := idx+1 < len(runes) && unicode.IsLower(runes[idx+1])
+			if !unicode.IsUpper(prev) || isNextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// PascalCase converts the identifier to PascalCase (like "HTTPServer"
+// for "http_server"). Known initialisms (like "ID" or "HTTP") are kept
+// upper-cased.
+func PascalCase(s string) string {
+	var result strings.Builder
+	for _, word := range splitWords(s) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			result.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		result.WriteString(string(runes))
+	}
+	return result.String()
+}
+
+// CamelCase converts the identifier to camelCase (like "httpServer" for
+// "HTTPServer").
+func CamelCase(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + PascalCase(strings.Join(words[1:], "_"))
+}
+
+// SnakeCase converts the identifier to snake_case (like "http_server" for
+// "HTTPServer").
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// KebabCase converts the identifier to kebab-case (like "http-server" for
+// "HTTPServer").
+func KebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// commonInitialisms are the initialisms kept upper-cased by PascalCase
+// and CamelCase (the list of golint).
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+var templateErrorRegexp = regexp.MustCompile(`(?s)^template: (.*?):([0-9]+):(?:([0-9]+):)? (.*)$`)
+
+// newErrTemplate converts an error of text/template to ErrTemplate.
+func newErrTemplate(name string, err error) error {
+	result := ErrTemplate{Err: err, Message: err.Error()}
+	result.Position.Filename = name
+	if match := templateErrorRegexp.FindStringSubmatch(err.Error()); match != nil {
+		result.Position.Filename = match[1]
+		result.Position.Line, _ = strconv.Atoi(match[2])
+		result.Position.Column, _ = strconv.Atoi(match[3])
+		result.Message = match[4]
+	}
+	return result
+}
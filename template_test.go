@@ -0,0 +1,86 @@
+package gosrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestTemplate(t *testing.T) {
+	pkg := openTestPackage(t, "template")
+
+	tmpl, err := gosrc.ParseTemplate("getters.tmpl", `
+{{- $recv := receiverName .Struct }}
+{{- $fields := .Struct.Fields }}
+{{- range $fields }}
+// {{ pascalCase .Name }} returns the value of {{ .Name }} ({{ tag . "json" | quote }}).
+func ({{ $recv }} *{{ $.Struct.Name }}) Get{{ pascalCase .Name }}() {{ qualify . }} {
+	if {{ $recv }} == nil {
+		return {{ zeroValue . }}
+	}
+	return {{ $recv }}.{{ .Name }}
+}
+{{ end }}`)
+	require.NoError(t, err)
+
+	file, err := tmpl.GenerateForDirective(pkg, "gen:getters", "gen-test", nil)
+	require.NoError(t, err)
+	b, err := file.Bytes()
+	require.NoError(t, err)
+	require.Contains(t, string(b), `import (
+	"time"
+)`)
+	require.Contains(t, string(b), `// ID returns the value of ID ("id").
+func (u *User) GetID() int {
+	if u == nil {
+		return 0
+	}
+	return u.ID
+}`)
+	require.Contains(t, string(b), `// Created returns the value of Created ("").
+func (u *User) GetCreated() time.Time {
+	if u == nil {
+		return time.Time{}
+	}`)
+	require.Contains(t, string(b), "func (u *User) GetHTTPAddr() *string {\n\tif u == nil {\n\t\treturn nil")
+	require.Contains(t, string(b), "func (u *User) GetTags() []string {")
+	require.NotContains(t, string(b), "fixture")
+
+	tmpl, err = gosrc.ParseTemplate("zero.tmpl", `
+{{- range .Structs }}
+{{- range .Fields }}
+var _ = {{ zeroValue . }}
+{{- end }}
+{{- end }}`)
+	require.NoError(t, err)
+	zeroFile := pkg.NewGeneratedFile("gen-test")
+	require.NoError(t, tmpl.Execute(zeroFile, gosrc.TemplateData{Package: pkg}))
+	b, err = zeroFile.Bytes()
+	require.NoError(t, err)
+	require.Contains(t, string(b), "var _ = time.Time{}")
+	require.NotContains(t, string(b), "float64")
+
+	_, err = gosrc.ParseTemplate("broken.tmpl", "line1\n{{ if }}")
+	var errTemplate gosrc.ErrTemplate
+	require.ErrorAs(t, err, &errTemplate)
+	require.Equal(t, "broken.tmpl", errTemplate.Position.Filename)
+	require.Equal(t, 2, errTemplate.Position.Line)
+
+	tmpl, err = gosrc.ParseTemplate("exec.tmpl", "\n\n  {{ zeroValue 1 }}")
+	require.NoError(t, err)
+	err = tmpl.Execute(gosrc.NewGeneratedFile("example.com/x", "x", ""), nil)
+	require.ErrorAs(t, err, &errTemplate)
+	require.Equal(t, 3, errTemplate.Position.Line)
+	require.Equal(t, 5, errTemplate.Position.Column)
+	require.Contains(t, err.Error(), "exec.tmpl:3:5: ")
+}
+
+func TestCaseConversion(t *testing.T) {
+	require.Equal(t, "httpServerID", gosrc.CamelCase("HTTPServerID"))
+	require.Equal(t, "HTTPServerID", gosrc.PascalCase("http_server_id"))
+	require.Equal(t, "UserName", gosrc.PascalCase("userName"))
+	require.Equal(t, "http_server_id", gosrc.SnakeCase("HTTPServerID"))
+	require.Equal(t, "utf8-string", gosrc.KebabCase("UTF8String"))
+}
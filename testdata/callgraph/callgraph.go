@@ -0,0 +1,49 @@
+package callgraph
+
+import (
+	"strings"
+)
+
+// Handler handles requests.
+type Handler interface {
+	Handle(req string) string
+}
+
+// Upper is a Handler.
+type Upper struct{}
+
+// Handle implements Handler.
+func (Upper) Handle(req string) string {
+	return strings.ToUpper(normalize(req))
+}
+
+// Lower is a Handler.
+type Lower struct{}
+
+// Handle implements Handler.
+func (*Lower) Handle(req string) string {
+	return deprecated(req)
+}
+
+func normalize(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// Deprecated: use normalize.
+func deprecated(s string) string {
+	return strings.ToLower(s)
+}
+
+// Serve calls the handler.
+func Serve(handler Handler, req string) string {
+	return handler.Handle(req)
+}
+
+// Main is the entry point.
+func Main() {
+	convert := normalize
+	_ = convert(" a ")
+	func() {
+		Serve(Upper{}, "a")
+	}()
+}
@@ -0,0 +1,53 @@
+package directives
+
+// Kind is a kind.
+//
+//gen:enum prefix=Kind
+type Kind int
+
+// Kinds.
+//
+//gen:values
+const (
+	// KindA is A.
+	//gen:value name="A kind"
+	KindA = Kind(iota)
+	KindB //gen:value name=B
+)
+
+type (
+	// User is a user.
+	//
+	//gen:builder skip name=UserBuilder timeout=5s tags=a,b extra
+	//go:generate
+	User struct {
+		// ID is an identifier.
+		//db:column primary name=id
+		ID int
+
+		Name string //db:column name="user name"
+
+		// Broken has a malformed directive.
+		//db:column name="unterminated
+		Broken string
+
+		// See http://example.com, TODO: do not treat as directives.
+		//nolint
+		Comment string
+	}
+
+	// Empty is an empty structure.
+	Empty struct{}
+)
+
+// Store stores values.
+type Store interface {
+	// Get returns a value.
+	//rpc:method timeout=1s
+	Get(key string) string
+}
+
+// Handle handles.
+//
+//http:route GET /users
+func Handle() {}
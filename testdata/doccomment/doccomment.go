@@ -0,0 +1,45 @@
+// Package doccomment is used to test parsing of doc comments.
+package doccomment
+
+import (
+	stdstrings "strings"
+)
+
+// Config is a configuration, see [Config.Validate], [Config.Name] and
+// [stdstrings.Builder].
+//
+// # Usage
+//
+// Steps:
+//   - create with [New];
+//   - call [Config.Validate].
+//
+// Example:
+//
+//	cfg := New()
+//	cfg.Validate()
+//
+//gen:builder
+type Config struct {
+	// Name is the name.
+	Name string // required
+
+	Tags []string
+}
+
+// New returns a new [Config].
+func New() *Config {
+	return &Config{Name: stdstrings.TrimSpace(" ")}
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	return nil
+}
+
+// Limits.
+const (
+	// MaxLen is the maximal length.
+	MaxLen = 10
+	MinLen = 1 // the minimal length
+)
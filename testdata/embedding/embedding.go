@@ -0,0 +1,49 @@
+package embedding
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/embedding/other"
+)
+
+// Base is embedded into Entity.
+type Base struct {
+	*other.Meta
+	Version int `json:"version"`
+}
+
+// Audit is embedded into Entity.
+type Audit struct {
+	Version int
+	Author  string `json:"author"`
+}
+
+// Entity embeds Base and Audit.
+type Entity struct {
+	Base
+	Audit `json:"audit"`
+	Name  string `json:"name"`
+}
+
+// Node refers to itself.
+type Node struct {
+	*Node
+	Value int
+}
+
+// GetVersion returns the version.
+func (b Base) GetVersion() int {
+	return b.Version
+}
+
+// Touch updates the audit information.
+func (a *Audit) Touch(author string) {
+	a.Author = author
+}
+
+// Holder has fields of types with methods.
+type Holder struct {
+	Entity   Entity
+	Audit    *Audit
+	Stringer interface {
+		String() string
+	}
+}
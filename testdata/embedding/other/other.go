@@ -0,0 +1,17 @@
+package other
+
+// Meta is a structure embedded from another package.
+type Meta struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetID returns the ID.
+func (m Meta) GetID() string {
+	return m.ID
+}
+
+// SetID sets the ID.
+func (m *Meta) SetID(id string) {
+	m.ID = id
+}
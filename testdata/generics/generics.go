@@ -0,0 +1,59 @@
+package generics
+
+import (
+	"fmt"
+)
+
+// Number is a constraint for numeric types.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// List is a generic linked list.
+type List[T any] struct {
+	head *element[T]
+	Len  int
+}
+
+type element[T any] struct {
+	next  *element[T]
+	Value T
+}
+
+// Push adds a value to the beginning of the list.
+func (l *List[T]) Push(v T) {
+	l.head = &element[T]{next: l.head, Value: v}
+	l.Len++
+}
+
+// Pair is a generic type with two type parameters.
+type Pair[K comparable, V fmt.Stringer] struct {
+	Key    K
+	Values map[K][]V
+}
+
+// Get returns the first value.
+func (p Pair[K, V]) Get() V {
+	return p.Values[p.Key][0]
+}
+
+// Sum returns the sum of the values.
+func Sum[N Number](values ...N) N {
+	var result N
+	for _, v := range values {
+		result += v
+	}
+	return result
+}
+
+// Integer is a constraint intersecting Number with ~int.
+type Integer interface {
+	Number
+	~int
+}
+
+var (
+	ints  List[int]
+	total = Sum[float64](1, 2)
+	pairs []Pair[string, fmt.Stringer]
+)
@@ -0,0 +1,43 @@
+package implementation
+
+import (
+	"io"
+)
+
+// Plugin is implemented by plugins.
+type Plugin interface {
+	Name() string
+	Start() error
+}
+
+// ValuePlugin implements Plugin by value.
+type ValuePlugin struct{}
+
+// Name implements Plugin.
+func (ValuePlugin) Name() string { return "value" }
+
+// Start implements Plugin.
+func (ValuePlugin) Start() error { return nil }
+
+// PointerPlugin implements Plugin only by pointer.
+type PointerPlugin struct{}
+
+// Name implements Plugin.
+func (*PointerPlugin) Name() string { return "pointer" }
+
+// Start implements Plugin.
+func (*PointerPlugin) Start() error { return nil }
+
+// AlmostPlugin lacks Start and has a wrong Name.
+type AlmostPlugin struct{}
+
+// Name does not implement Plugin.
+func (AlmostPlugin) Name() []byte { return nil }
+
+// Writer implements io.Writer.
+type Writer struct{}
+
+// Write implements io.Writer.
+func (*Writer) Write(b []byte) (int, error) { return len(b), nil }
+
+var _ io.Writer = (*Writer)(nil)
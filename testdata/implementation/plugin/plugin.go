@@ -0,0 +1,11 @@
+package plugin
+
+// Config is a configuration of a plugin.
+type Config struct {
+	Name string
+}
+
+// Plugin is implemented by plugins declared in other packages.
+type Plugin interface {
+	Run(c Config) error
+}
@@ -0,0 +1,17 @@
+package runner
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/implementation/plugin"
+)
+
+// Runner implements plugin.Plugin.
+type Runner struct{}
+
+// Run implements plugin.Plugin.
+func (Runner) Run(plugin.Config) error { return nil }
+
+// Broken has Run with a wrong signature.
+type Broken struct{}
+
+// Run does not implement plugin.Plugin.
+func (Broken) Run(plugin.Config) string { return "" }
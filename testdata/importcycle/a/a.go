@@ -0,0 +1,10 @@
+package a
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/importcycle/b"
+)
+
+// A calls B.
+func A() {
+	b.B()
+}
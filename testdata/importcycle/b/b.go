@@ -0,0 +1,10 @@
+package b
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/importcycle/a"
+)
+
+// B calls A.
+func B() {
+	a.A()
+}
@@ -0,0 +1,12 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/xaionaro-go/gosrc/testdata/imports/service"
+)
+
+// Run runs the application.
+func Run() {
+	fmt.Println(service.Get("a"))
+}
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/imports/store"
+)
+
+// Get returns the value.
+func Get(key string) string {
+	return store.Key(key)
+}
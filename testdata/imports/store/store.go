@@ -0,0 +1,10 @@
+package store
+
+import (
+	"strings"
+)
+
+// Key normalizes the key.
+func Key(key string) string {
+	return strings.ToLower(key)
+}
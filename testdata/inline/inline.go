@@ -0,0 +1,23 @@
+package inline
+
+// Config has inline structures and interfaces.
+type Config struct {
+	// Server is the server configuration.
+	Server struct {
+		Addr string `yaml:"addr"`
+
+		// TLS is the TLS configuration.
+		TLS *struct {
+			Cert string `yaml:"cert"`
+		} `yaml:"tls"`
+	} `yaml:"server"`
+
+	Routes []struct {
+		Path string
+	}
+
+	Logger interface {
+		// Printf prints a formatted message.
+		Printf(format string, args ...any)
+	}
+}
@@ -0,0 +1,30 @@
+package layout
+
+// Bad has a lot of padding.
+type Bad struct {
+	// A is a flag.
+	A bool // first
+	B int64
+	C bool
+	D int32
+	E struct{}
+}
+
+// Good is already optimal.
+type Good struct {
+	B int64
+	D int32
+	A bool
+	C bool
+}
+
+// Grouped declares fields together.
+type Grouped struct {
+	A, B bool
+
+	// C is a counter.
+	C int64 `json:"c"` // in bytes
+}
+
+// Unformatted is not formatted with gofmt (and should be kept as is).
+var Unformatted = []int{1,2,3}
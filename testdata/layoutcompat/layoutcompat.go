@@ -0,0 +1,35 @@
+package layoutcompat
+
+// Header is shared with C code.
+//
+//gosrc:layout portable size=16 align=8
+type Header struct {
+	Magic uint32
+
+	//gosrc:layout offset=8 size=8
+	Length uint64
+}
+
+// Portable has the same layout everywhere.
+//
+//gosrc:layout portable size=8
+type Portable struct {
+	A    uint32
+	B    uint16
+	C, D uint8
+}
+
+// Message depends on the word size.
+//
+//gosrc:layout goarch=amd64,arm64 size=32
+type Message struct {
+	ID   int
+	Data []byte
+}
+
+// Broken has a malformed directive.
+//
+//gosrc:layout offset=1
+type Broken struct {
+	A int
+}
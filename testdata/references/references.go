@@ -0,0 +1,45 @@
+package references
+
+import (
+	"github.com/xaionaro-go/gosrc/testdata/embedding/other"
+)
+
+// ID is an identifier.
+type ID int64
+
+// User is a user.
+type User struct {
+	other.Meta
+	Name string
+	Age  int
+}
+
+// Admin embeds User.
+type Admin struct {
+	*User
+}
+
+// NewUser constructs a User.
+func NewUser(name string) *User {
+	return &User{Name: name}
+}
+
+// Rename changes the name.
+func (u *User) Rename(name string) {
+	u.Name = name
+	u.Age++
+}
+
+func use() ID {
+	u := NewUser("a")
+	u.Rename(u.Name)
+	setAge(&u.Age)
+	f := NewUser
+	_ = f
+	_ = User{}
+	return ID(u.Age)
+}
+
+func setAge(age *int) {
+	*age = 1
+}
@@ -0,0 +1,32 @@
+package source
+
+// Point is a point.
+type Point struct {
+	// X is the horizontal coordinate.
+	X int `json:"x"` // in pixels
+	Y int
+}
+
+// Shapes.
+type (
+	// Circle is a circle.
+	Circle struct {
+		Center Point
+		Radius int
+	}
+
+	Square struct{ Side int } // a square
+)
+
+// Len returns the length.
+func (p Point) Len() int {
+	return p.X + p.Y
+}
+
+// Size is a size.
+const Size = 10 // pixels
+
+const (
+	// Small is small.
+	Small = 1
+)
@@ -0,0 +1,27 @@
+package sourceedit
+
+import "fmt"
+
+// User is a user.
+//
+//gen:builder
+type User struct {
+	// ID is an identifier.
+	ID int `json:"id" db:"id"` // primary key
+
+	// Name is a name.
+	Name string
+
+	Legacy bool // to be removed
+}
+
+// String just implements fmt.Stringer
+func (u User) String() string {
+	return fmt.Sprint(u.ID)
+}
+
+/* a free-floating comment */
+
+func helper() {}
+
+func unformatted() { _ = []int{1,2,3} }
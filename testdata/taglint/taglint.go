@@ -0,0 +1,42 @@
+package taglint
+
+// Meta is embedded.
+type Meta struct {
+	ID      int    `json:"id"`
+	Created string `json:"created_at"`
+}
+
+// User is a user.
+type User struct {
+	Meta
+	UserID   int    `json:"id" yaml:"id"`
+	Name     string `json:"name,omitemtpy" yaml:"name"`
+	LastName string `json:"lastName" yaml:"last_name"`
+	Email    string `json:"email_address" yaml:"email_address"`
+	password string `json:"password"`
+	Internal string `json:"-" yaml:"-"`
+	Broken   string `json:"broken`
+	Opts     struct {
+		A int `json:"a"`
+		B int `json:"a"`
+	} `json:"opts"`
+}
+
+// Audit is embedded.
+type Audit struct {
+	Created string `json:"created_at"`
+}
+
+// Account has fields with the same name at the same depth.
+type Account struct {
+	Meta
+	Audit
+}
+
+// Clean has no problems.
+type Clean struct {
+	Meta       `json:"meta"`
+	ID         int    `json:"id"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	unexported int
+}
@@ -0,0 +1,19 @@
+package tags
+
+// Base is embedded.
+type Base struct {
+	ID int `json:"id" db:"id"`
+}
+
+// Config is a configuration.
+type Config struct {
+	Base
+	Name     string   `json:"name,omitempty" yaml:"name,flow" db:"-"`
+	Port     int      `env:"PORT,required" envDefault:"8080" validate:"required,min=1,max=65535"`
+	Secret   string   `json:"-" validate:"-"`
+	Hosts    []string `yaml:",inline"`
+	Timeout  int      `json:"timeout,string"`
+	Broken   string   `json:"broken`
+	Repeated string   `json:"a" json:"b"`
+	Quoted   string   "json:\"quoted\""
+}
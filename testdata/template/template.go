@@ -0,0 +1,22 @@
+package template
+
+import (
+	"time"
+)
+
+// User is a user.
+//
+//gen:getters
+type User struct {
+	ID       int           `json:"id"`
+	Name     string        `json:"name,omitempty"`
+	Timeout  time.Duration `json:"timeout"`
+	Created  time.Time
+	Tags     []string
+	HTTPAddr *string
+}
+
+// Plain has no directive.
+type Plain struct {
+	A int
+}
@@ -0,0 +1,8 @@
+package template
+
+// fixture is declared only in a test file.
+//
+//gen:getters
+type fixture struct {
+	Value float64
+}
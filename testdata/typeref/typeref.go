@@ -0,0 +1,24 @@
+package typeref
+
+import (
+	"net/http"
+	"time"
+)
+
+// Box is a generic container.
+type Box[T any] struct {
+	Value T
+}
+
+// Kinds has fields of various kinds.
+type Kinds struct {
+	Handler  func(http.ResponseWriter, *http.Request) error
+	Headers  map[time.Duration]*http.Header
+	Boxes    [4]**Box[time.Time]
+	Events   <-chan time.Time
+	Iface    interface{ Close() error }
+	Counter  uint64
+	Inline   struct{ A int }
+	Variadic func(string, ...int)
+	time.Location
+}
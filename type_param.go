@@ -0,0 +1,282 @@
+package gosrc
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// TypeParam represents one type parameter of a generic type or function.
+type TypeParam struct {
+	*ast.Ident
+
+	// Constraint is the constraint expression as it is written in
+	// the source code. It is nil for type parameters declared in
+	// method receivers (they reuse the constraints of the receiver type).
+	Constraint ast.Expr
+
+	Index uint
+
+	// Type is the type-checked type parameter. It is nil if the package
+	// was opened with "onlyFiles".
+	Type *types.TypeParam
+}
+
+// TypeParams is a set of TypeParam-s.
+type TypeParams []*TypeParam
+
+func newTypeParams(file *File, fieldList *ast.FieldList) TypeParams {
+	if fieldList == nil {
+		return nil
+	}
+	var result TypeParams
+	for _, field := range fieldList.List {
+		for _, name := range field.Names {
+			result = append(result, &TypeParam{
+				Ident:      name,
+				Constraint: field.Type,
+				Index:      uint(len(result)),
+				Type:       file.typeParamOf(name),
+			})
+		}
+	}
+	return result
+}
+
+// Name returns the name of the type parameter.
+func (typeParam TypeParam) Name() string {
+	return typeParam.Ident.Name
+}
+
+// String just implements fmt.Stringer
+func (typeParam TypeParam) String() string {
+	if typeParam.Type == nil {
+		return typeParam.Name()
+	}
+	return typeParam.Name() + " " + typeParam.Type.Constraint().String()
+}
+
+// ConstraintInterface returns the interface of the constraint
+// of the type parameter (or nil if the type information is not available).
+func (typeParam TypeParam) ConstraintInterface() *types.Interface {
+	if typeParam.Type == nil {
+		return nil
+	}
+	iface, _ := typeParam.Type.Constraint().Underlying().(*types.Interface)
+	return iface
+}
+
+// IsComparable returns true if the constraint of the type parameter
+// requires the type to be comparable.
+func (typeParam TypeParam) IsComparable() bool {
+	iface := typeParam.ConstraintInterface()
+	if iface == nil {
+		return false
+	}
+	return iface.IsComparable()
+}
+
+// TypeSet returns the terms of the type set of the type parameter.
+//
+// The second returned value is false if the type set is not restricted
+// by type terms (for example for constraints "any" or "fmt.Stringer"),
+// in this case any type implementing the methods of the constraint
+// satisfies it.
+func (typeParam TypeParam) TypeSet() ([]*types.Term, bool) {
+	iface := typeParam.ConstraintInterface()
+	if iface == nil {
+		return nil, false
+	}
+	return interfaceTypeTerms(iface)
+}
+
+// Methods returns the methods required by the constraint of the type
+// parameter.
+func (typeParam TypeParam) Methods() []*types.Func {
+	iface := typeParam.ConstraintInterface()
+	if iface == nil {
+		return nil
+	}
+	var result []*types.Func
+	for idx := 0; idx < iface.NumMethods(); idx++ {
+		result = append(result, iface.Method(idx))
+	}
+	return result
+}
+
+// FindByName returns the type parameter with the specified name
+// (or nil if there is no such type parameter).
+func (typeParams TypeParams) FindByName(name string) *TypeParam {
+	for _, typeParam := range typeParams {
+		if typeParam.Name() == name {
+			return typeParam
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the type parameters in the order of
+// their declaration.
+func (typeParams TypeParams) Names() []string {
+	var result []string
+	for _, typeParam := range typeParams {
+		result = append(result, typeParam.Name())
+	}
+	return result
+}
+
+// interfaceTypeTerms returns the intersection of the type terms
+// of all the embedded unions (and embedded interfaces) of the interface.
+func interfaceTypeTerms(iface *types.Interface) ([]*types.Term, bool) {
+	var (
+		result     []*types.Term
+		restricted bool
+	)
+	for idx := 0; idx < iface.NumEmbeddeds(); idx++ {
+		var (
+			terms         []*types.Term
+			hasRestricted bool
+		)
+		switch embedded := iface.EmbeddedType(idx).(type) {
+		case *types.Union:
+			for termIdx := 0; termIdx < embedded.Len(); termIdx++ {
+				term := embedded.Term(termIdx)
+				if embeddedIface, ok := term.Type().Underlying().(*types.Interface); ok {
+					subTerms, subRestricted := interfaceTypeTerms(embeddedIface)
+					if !subRestricted {
+						// a union with an unrestricted interface term
+						// does not restrict the type set.
+						terms, hasRestricted = nil, false
+						break
+					}
+					terms = append(terms, subTerms...)
+					hasRestricted = true
+					continue
+				}
+				terms = append(terms, term)
+				hasRestricted = true
+			}
+		default:
+			if embeddedIface, ok := embedded.Underlying().(*types.Interface); ok {
+				terms, hasRestricted = interfaceTypeTerms(embeddedIface)
+			} else {
+				terms, hasRestricted = []*types.Term{types.NewTerm(false, embedded)}, true
+			}
+		}
+		if !hasRestricted {
+			continue
+		}
+		if !restricted {
+			result, restricted = terms, true
+			continue
+		}
+		result = intersectTypeTerms(result, terms)
+	}
+	return result, restricted
+}
+
+func intersectTypeTerms(a, b []*types.Term) []*types.Term {
+	var result []*types.Term
+	for _, x := range a {
+		for _, y := range b {
+			if term := intersectTypeTerm(x, y); term != nil {
+				result = append(result, term)
+			}
+		}
+	}
+	return result
+}
+
+func intersectTypeTerm(x, y *types.Term) *types.Term {
+	switch {
+	case x.Tilde() && y.Tilde():
+		if types.Identical(x.Type(), y.Type()) {
+			return x
+		}
+	case x.Tilde():
+		if types.Identical(x.Type(), y.Type().Underlying()) {
+			return y
+		}
+	case y.Tilde():
+		if types.Identical(y.Type(), x.Type().Underlying()) {
+			return x
+		}
+	default:
+		if types.Identical(x.Type(), y.Type()) {
+			return x
+		}
+	}
+	return nil
+}
+
+// typeParamsOf returns all type parameters referenced by the type.
+func typeParamsOf(typ types.Type) []*types.TypeParam {
+	var result []*types.TypeParam
+	walkType(typ, func(typ types.Type) {
+		if typeParam, ok := typ.(*types.TypeParam); ok {
+			result = append(result, typeParam)
+		}
+	})
+	return result
+}
+
+// walkType calls callback for the type and for all the types it
+// consists of. Named types are not expanded (only their type arguments
+// are walked through).
+func walkType(typ types.Type, callback func(types.Type)) {
+	walkTypeVisited(typ, callback, map[types.Type]struct{}{})
+}
+
+func walkTypeVisited(typ types.Type, callback func(types.Type), visited map[types.Type]struct{}) {
+	if typ == nil {
+		return
+	}
+	if _, ok := visited[typ]; ok {
+		return
+	}
+	visited[typ] = struct{}{}
+	callback(typ)
+
+	walk := func(typ types.Type) {
+		walkTypeVisited(typ, callback, visited)
+	}
+	switch typ := typ.(type) {
+	case *types.Pointer:
+		walk(typ.Elem())
+	case *types.Slice:
+		walk(typ.Elem())
+	case *types.Array:
+		walk(typ.Elem())
+	case *types.Chan:
+		walk(typ.Elem())
+	case *types.Map:
+		walk(typ.Key())
+		walk(typ.Elem())
+	case *types.Tuple:
+		for idx := 0; idx < typ.Len(); idx++ {
+			walk(typ.At(idx).Type())
+		}
+	case *types.Signature:
+		walk(typ.Params())
+		walk(typ.Results())
+	case *types.Struct:
+		for idx := 0; idx < typ.NumFields(); idx++ {
+			walk(typ.Field(idx).Type())
+		}
+	case *types.Interface:
+		for idx := 0; idx < typ.NumExplicitMethods(); idx++ {
+			walk(typ.ExplicitMethod(idx).Type())
+		}
+		for idx := 0; idx < typ.NumEmbeddeds(); idx++ {
+			walk(typ.EmbeddedType(idx))
+		}
+	case *types.Union:
+		for idx := 0; idx < typ.Len(); idx++ {
+			walk(typ.Term(idx).Type())
+		}
+	case *types.Named:
+		typeArgs := typ.TypeArgs()
+		for idx := 0; idx < typeArgs.Len(); idx++ {
+			walk(typeArgs.At(idx))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package gosrc
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// TypeKind is a kind of a type referenced by TypeRef.
+type TypeKind uint
+
+const (
+	// TypeKindInvalid is an undefined kind (for example if there is no
+	// type information).
+	TypeKindInvalid = TypeKind(iota)
+
+	// TypeKindBasic is a kind of predeclared types like int, string
+	// and unsafe.Pointer.
+	TypeKindBasic
+
+	// TypeKindNamed is a kind of defined types like "time.Time",
+	// "error" or "Box[int]".
+	TypeKindNamed
+
+	// TypeKindTypeParam is a kind of type parameters.
+	TypeKindTypeParam
+
+	// TypeKindPointer is a kind of pointers.
+	TypeKindPointer
+
+	// TypeKindSlice is a kind of slices.
+	TypeKindSlice
+
+	// TypeKindArray is a kind of arrays.
+	TypeKindArray
+
+	// TypeKindMap is a kind of maps.
+	TypeKindMap
+
+	// TypeKindChan is a kind of channels.
+	TypeKindChan
+
+	// TypeKindFunc is a kind of function types.
+	TypeKindFunc
+
+	// TypeKindStruct is a kind of unnamed structures.
+	TypeKindStruct
+
+	// TypeKindInterface is a kind of unnamed interfaces.
+	TypeKindInterface
+)
+
+// String just implements fmt.Stringer
+func (kind TypeKind) String() string {
+	switch kind {
+	case TypeKindInvalid:
+		return "invalid"
+	case TypeKindBasic:
+		return "basic"
+	case TypeKindNamed:
+		return "named"
+	case TypeKindTypeParam:
+		return "type_param"
+	case TypeKindPointer:
+		return "pointer"
+	case TypeKindSlice:
+		return "slice"
+	case TypeKindArray:
+		return "array"
+	case TypeKindMap:
+		return "map"
+	case TypeKindChan:
+		return "chan"
+	case TypeKindFunc:
+		return "func"
+	case TypeKindStruct:
+		return "struct"
+	case TypeKindInterface:
+		return "interface"
+	default:
+		return fmt.Sprintf("unknown_kind_%d", uint(kind))
+	}
+}
+
+// TypeRef is a structured reference to a type. Named types are not
+// expanded (use Type.Underlying() to get their definitions), so
+// the tree is always finite.
+type TypeRef struct {
+	Kind TypeKind
+
+	// Type is the original type.
+	Type types.Type
+
+	// PkgPath is the path of the package where the named type is
+	// defined (empty for predeclared and unnamed types).
+	PkgPath string
+
+	// Name is the name of a named type, of a predeclared type
+	// or of a type parameter (without type arguments).
+	Name string
+
+	// TypeArgs are type arguments of an instantiated generic type.
+	TypeArgs []*TypeRef
+
+	// Elem is the element type of a pointer, slice, array, channel,
+	// or the value type of a map.
+	Elem *TypeRef
+
+	// Key is the key type of a map.
+	Key *TypeRef
+
+	// Len is the length of an array.
+	Len int64
+
+	// ChanDir is the direction of a channel.
+	ChanDir types.ChanDir
+
+	// Params are types of parameters of a function type.
+	Params []*TypeRef
+
+	// Results are types of results of a function type.
+	Results []*TypeRef
+
+	// Variadic is true if the function type is variadic (the last
+	// of Params is a slice in this case).
+	Variadic bool
+}
+
+// NewTypeRef returns the structured reference to the type.
+func NewTypeRef(typ types.Type) *TypeRef {
+	ref := &TypeRef{Type: typ}
+	if typ == nil {
+		return ref
+	}
+
+	switch casted := types.Unalias(typ).(type) {
+	case *types.Basic:
+		ref.Kind = TypeKindBasic
+		ref.Name = casted.Name()
+		if casted.Kind() == types.UnsafePointer {
+			ref.PkgPath = "unsafe"
+			ref.Name = "Pointer"
+		}
+	case *types.Named:
+		ref.Kind = TypeKindNamed
+		ref.Name = casted.Obj().Name()
+		if casted.Obj().Pkg() != nil {
+			ref.PkgPath = casted.Obj().Pkg().Path()
+		}
+		typeArgs := casted.TypeArgs()
+		for idx := 0; idx < typeArgs.Len(); idx++ {
+			ref.TypeArgs = append(ref.TypeArgs, NewTypeRef(typeArgs.At(idx)))
+		}
+	case *types.TypeParam:
+		ref.Kind = TypeKindTypeParam
+		ref.Name = casted.Obj().Name()
+	case *types.Pointer:
+		ref.Kind = TypeKindPointer
+		ref.Elem = NewTypeRef(casted.Elem())
+	case *types.Slice:
+		ref.Kind = TypeKindSlice
+		ref.Elem = NewTypeRef(casted.Elem())
+	case *types.Array:
+		ref.Kind = TypeKindArray
+		ref.Elem = NewTypeRef(casted.Elem())
+		ref.Len = casted.Len()
+	case *types.Map:
+		ref.Kind = TypeKindMap
+		ref.Key = NewTypeRef(casted.Key())
+		ref.Elem = NewTypeRef(casted.Elem())
+	case *types.Chan:
+		ref.Kind = TypeKindChan
+		ref.Elem = NewTypeRef(casted.Elem())
+		ref.ChanDir = casted.Dir()
+	case *types.Signature:
+		ref.Kind = TypeKindFunc
+		ref.Params = newTypeRefsOfTuple(casted.Params())
+		ref.Results = newTypeRefsOfTuple(casted.Results())
+		ref.Variadic = casted.Variadic()
+	case *types.Struct:
+		ref.Kind = TypeKindStruct
+	case *types.Interface:
+		ref.Kind = TypeKindInterface
+	}
+	return ref
+}
+
+func newTypeRefsOfTuple(tuple *types.Tuple) []*TypeRef {
+	var result []*TypeRef
+	for idx := 0; idx < tuple.Len(); idx++ {
+		result = append(result, NewTypeRef(tuple.At(idx).Type()))
+	}
+	return result
+}
+
+// String returns the type as it is written in Go with full package paths
+// (like "map[github.com/my/pkg.Key][]*github.com/my/pkg.Value").
+func (ref *TypeRef) String() string {
+	if ref == nil || ref.Type == nil {
+		return ""
+	}
+	return ref.Type.String()
+}
+
+// QualifiedName returns the name of a named type prefixed with
+// the package path (like "github.com/my/pkg.Value"). For other types
+// it returns the same as String.
+func (ref *TypeRef) QualifiedName() string {
+	if ref == nil {
+		return ""
+	}
+	if ref.PkgPath == "" || ref.Name == "" {
+		return ref.String()
+	}
+	return ref.PkgPath + "." + ref.Name
+}
+
+// PointerDepth returns the amount of pointers to dereference to reach
+// a non-pointer type (for example 2 for "**int").
+func (ref *TypeRef) PointerDepth() int {
+	depth := 0
+	for ref != nil && ref.Kind == TypeKindPointer {
+		depth++
+		ref = ref.Elem
+	}
+	return depth
+}
+
+// Deref returns the type without pointers (for example "int" for "**int").
+func (ref *TypeRef) Deref() *TypeRef {
+	for ref != nil && ref.Kind == TypeKindPointer {
+		ref = ref.Elem
+	}
+	return ref
+}
+
+// Item returns the type of items referenced by the type: it walks
+// through pointers, slices, arrays, channels and values of maps. For
+// example, for "map[string][]*time.Time" it returns "time.Time".
+func (ref *TypeRef) Item() *TypeRef {
+	for ref != nil && ref.Elem != nil {
+		ref = ref.Elem
+	}
+	return ref
+}
+
+// IsNamed returns true if the type is a named type (including
+// instantiated generic types).
+func (ref *TypeRef) IsNamed() bool {
+	return ref != nil && ref.Kind == TypeKindNamed
+}
+
+// TypeNameValue returns the name and the package path of the type. For
+// unnamed types other than predeclared the name is the type string.
+func (ref *TypeRef) TypeNameValue() TypeNameValue {
+	if ref == nil || ref.Type == nil {
+		return TypeNameValue{}
+	}
+	switch ref.Kind {
+	case TypeKindBasic, TypeKindNamed, TypeKindTypeParam:
+		return TypeNameValue{
+			Name: ref.Name,
+			Path: ref.PkgPath,
+		}
+	default:
+		return TypeNameValue{
+			Name: ref.Type.String(),
+		}
+	}
+}
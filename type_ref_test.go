@@ -0,0 +1,52 @@
+package gosrc_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/gosrc"
+)
+
+func TestFieldTypeRef(t *testing.T) {
+	pkg := openTestPackage(t, "typeref")
+	fields, err := findStruct(t, pkg, "Kinds").Fields()
+	require.NoError(t, err)
+	require.Len(t, fields, 9)
+
+	handler := fields[0]
+	require.True(t, handler.IsFunc())
+	ref := handler.TypeRef()
+	require.Equal(t, gosrc.TypeKindFunc, ref.Kind)
+	require.Len(t, ref.Params, 2)
+	require.Equal(t, "net/http.Request", ref.Params[1].Deref().QualifiedName())
+	require.Equal(t, "error", ref.Results[0].Name)
+
+	headers := fields[1]
+	require.True(t, headers.IsMap())
+	ref = headers.TypeRef()
+	require.Equal(t, "time.Duration", ref.Key.QualifiedName())
+	require.Equal(t, 1, ref.Elem.PointerDepth())
+	require.Equal(t, gosrc.TypeNameValue{Name: "Header", Path: "net/http"}, headers.ItemTypeName())
+
+	boxes := fields[2]
+	require.True(t, boxes.IsArray())
+	ref = boxes.TypeRef()
+	require.Equal(t, int64(4), ref.Len)
+	require.Equal(t, 2, ref.Elem.PointerDepth())
+	box := ref.Item()
+	require.Equal(t, "Box", box.Name)
+	require.Len(t, box.TypeArgs, 1)
+	require.Equal(t, "time.Time", box.TypeArgs[0].QualifiedName())
+
+	events := fields[3]
+	require.True(t, events.IsChan())
+	require.Equal(t, types.RecvOnly, events.TypeRef().ChanDir)
+
+	require.True(t, fields[4].IsInterface())
+	require.True(t, fields[5].IsBasic())
+	require.True(t, fields[6].IsStruct())
+	require.Equal(t, gosrc.TypeKindStruct, fields[6].TypeRef().Kind)
+	require.True(t, fields[7].TypeRef().Variadic)
+	require.Equal(t, "Location", fields[8].Name())
+}